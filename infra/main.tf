@@ -237,6 +237,7 @@ resource "aws_iam_policy" "ecs_execution_policy" {
                     data.aws_secretsmanager_secret.db_secret.arn,
                     data.aws_secretsmanager_secret.cognito_secret.arn,
                     data.aws_secretsmanager_secret.token_secret.arn,
+                    aws_secretsmanager_secret.payment_webhook_secret.arn,
                 ]
                 },
                 {
@@ -390,6 +391,8 @@ module "ecs" {
     depends_on = [ module.elb ]
     market_target_group_arn = module.elb.market_target_group_arn
     lands_target_group_arn = module.elb.lands_target_group_arn
+    payment_provider = var.payment_provider
+    payment_webhook_secret_arn = aws_secretsmanager_secret.payment_webhook_secret.arn
 }
 
 resource "aws_security_group" "internal_sg" {
@@ -575,4 +578,18 @@ resource "aws_secretsmanager_secret" "token_secret" {
 resource "aws_secretsmanager_secret_version" "token_secret_version" {
     secret_id     = aws_secretsmanager_secret.token_secret.id
     secret_string = random_password.token_secret.result
+}
+
+resource "random_password" "payment_webhook_secret" {
+    length = 32
+    special  = false
+}
+
+resource "aws_secretsmanager_secret" "payment_webhook_secret" {
+    name = "payment_webhook_secret"
+}
+
+resource "aws_secretsmanager_secret_version" "payment_webhook_secret_version" {
+    secret_id     = aws_secretsmanager_secret.payment_webhook_secret.id
+    secret_string = random_password.payment_webhook_secret.result
 }
\ No newline at end of file
@@ -120,6 +120,15 @@ resource "aws_apigatewayv2_route" "market_private_protected" {
     authorizer_id      = aws_apigatewayv2_authorizer.lambda_authorizer.id
 }
 
+# Payment provider callbacks carry no user session; the service checks their
+# HMAC signature instead
+resource "aws_apigatewayv2_route" "market_payment_webhook" {
+    api_id    = aws_apigatewayv2_api.main.id
+    route_key = "POST /api/market/payments/webhook"
+    target    = "integrations/${aws_apigatewayv2_integration.private_elb.id}"
+    authorization_type = "NONE"
+}
+
 resource "aws_apigatewayv2_route" "market_public" {
     api_id    = aws_apigatewayv2_api.main.id
     route_key = "GET /api/market/{proxy+}"
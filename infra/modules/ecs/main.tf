@@ -88,7 +88,14 @@ resource "aws_ecs_task_definition" "market_task" {
     memory                   = var.memory  
     execution_role_arn       =  var.execution_role_arn
     task_role_arn            = var.task_role_arn
-    container_definitions    = jsonencode([var.container_definitions[1]])
+    container_definitions    = jsonencode([merge(var.container_definitions[1], {
+        environment = [
+            { name = "PAYMENT_PROVIDER", value = var.payment_provider },
+        ]
+        secrets = [
+            { name = "PAYMENT_WEBHOOK_SECRET", valueFrom = var.payment_webhook_secret_arn },
+        ]
+    })])
 }
 
 # Service for userss in the first private subnet
@@ -119,4 +119,13 @@ variable "lands_target_group_arn"{
 
 variable "market_target_group_arn" {
     type = string
-}
\ No newline at end of file
+}
+variable "payment_provider" {
+    description = "Payment provider the market service charges buyers through"
+    type        = string
+}
+
+variable "payment_webhook_secret_arn" {
+    description = "ARN of the Secrets Manager secret that signs payment webhooks"
+    type        = string
+}
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_auction_bids_auction;
+
+DROP TABLE IF EXISTS auction_proxy_bids;
+
+ALTER TABLE auction_bids DROP COLUMN IF EXISTS automatic;
@@ -0,0 +1,17 @@
+ALTER TABLE auction_bids ADD COLUMN automatic BOOLEAN NOT NULL DEFAULT false;
+
+-- standing maximum bids raised automatically by the marketplace
+CREATE TABLE auction_proxy_bids (
+    id UUID DEFAULT uuid_generate_v4() NOT NULL,
+    auction_id UUID NOT NULL,
+    bidder_id UUID NOT NULL,
+    max_amount DECIMAL(10,2) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    UNIQUE (auction_id, bidder_id),
+    FOREIGN KEY (auction_id) REFERENCES credit_auctions(id),
+    FOREIGN KEY (bidder_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_auction_bids_auction ON auction_bids(auction_id);
@@ -0,0 +1,5 @@
+ALTER TABLE credit_auctions
+    DROP COLUMN IF EXISTS price_drop_interval_seconds,
+    DROP COLUMN IF EXISTS auction_type;
+
+DROP TYPE IF EXISTS auction_type;
@@ -0,0 +1,5 @@
+CREATE TYPE auction_type AS ENUM ('english', 'sealed_second_price', 'dutch');
+
+ALTER TABLE credit_auctions
+    ADD COLUMN auction_type auction_type NOT NULL DEFAULT 'english',
+    ADD COLUMN price_drop_interval_seconds INT; -- dutch auctions only
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS idx_buy_orders_open;
+DROP INDEX IF EXISTS idx_buy_orders_buyer;
+
+ALTER TABLE purchases DROP COLUMN IF EXISTS buy_order_id;
+
+DROP TABLE IF EXISTS buy_orders;
+
+DROP TYPE IF EXISTS buy_order_status;
@@ -0,0 +1,24 @@
+CREATE TYPE buy_order_status AS ENUM ('open', 'filled', 'cancelled');
+
+-- standing buy orders filled by the matching engine
+CREATE TABLE buy_orders (
+    id UUID DEFAULT uuid_generate_v4() NOT NULL,
+    buyer_id UUID NOT NULL,
+    quantity DECIMAL(10,2) NOT NULL,
+    quantity_filled DECIMAL(10,2) DEFAULT 0,
+    max_price_per_credit DECIMAL(10,2) NOT NULL,
+    biome_type VARCHAR(50),
+    location VARCHAR(100),
+    min_vintage_year INT,
+    verification_standard VARCHAR(50),
+    status buy_order_status DEFAULT 'open',
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (buyer_id) REFERENCES users(id)
+);
+
+ALTER TABLE purchases ADD COLUMN buy_order_id UUID REFERENCES buy_orders(id);
+
+CREATE INDEX idx_buy_orders_buyer ON buy_orders(buyer_id);
+CREATE INDEX idx_buy_orders_open ON buy_orders(max_price_per_credit DESC, created_at) WHERE status = 'open';
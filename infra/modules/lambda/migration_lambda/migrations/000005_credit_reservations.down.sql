@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_credit_reservations_held;
+
+DROP TABLE IF EXISTS credit_reservations;
+
+DROP TYPE IF EXISTS reservation_status;
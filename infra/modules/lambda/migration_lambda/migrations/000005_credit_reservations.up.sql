@@ -0,0 +1,23 @@
+CREATE TYPE reservation_status AS ENUM ('held', 'confirmed', 'released', 'expired');
+
+-- checkout holds on listed credits
+CREATE TABLE credit_reservations (
+    id UUID DEFAULT uuid_generate_v4() NOT NULL,
+    listing_id UUID NOT NULL,
+    carbon_credits_id UUID NOT NULL,
+    buyer_id UUID NOT NULL,
+    amount DECIMAL(10,2) NOT NULL,
+    price_per_credit DECIMAL(10,2) NOT NULL,
+    status reservation_status DEFAULT 'held',
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    purchase_id UUID,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (listing_id) REFERENCES credit_listings(id),
+    FOREIGN KEY (carbon_credits_id) REFERENCES carbon_credits(id),
+    FOREIGN KEY (buyer_id) REFERENCES users(id),
+    FOREIGN KEY (purchase_id) REFERENCES purchases(id)
+);
+
+CREATE INDEX idx_credit_reservations_held ON credit_reservations(carbon_credits_id, expires_at) WHERE status = 'held';
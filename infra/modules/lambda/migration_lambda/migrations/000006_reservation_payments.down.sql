@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_credit_reservations_payment_intent;
+
+ALTER TABLE credit_reservations DROP COLUMN IF EXISTS payment_intent_id;
@@ -0,0 +1,3 @@
+ALTER TABLE credit_reservations ADD COLUMN payment_intent_id VARCHAR(255);
+
+CREATE UNIQUE INDEX idx_credit_reservations_payment_intent ON credit_reservations(payment_intent_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_credit_wallet_purchase;
+
+ALTER TABLE purchases
+    DROP COLUMN IF EXISTS refunded_at,
+    DROP COLUMN IF EXISTS listing_id;
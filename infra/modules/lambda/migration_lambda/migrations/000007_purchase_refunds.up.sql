@@ -0,0 +1,5 @@
+ALTER TABLE purchases
+    ADD COLUMN listing_id UUID REFERENCES credit_listings(id),
+    ADD COLUMN refunded_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_credit_wallet_purchase ON credit_wallets(purchase_id);
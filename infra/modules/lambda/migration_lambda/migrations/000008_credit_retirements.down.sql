@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_credit_retirements_owner;
+
+DROP TRIGGER IF EXISTS credit_retirements_immutable ON credit_retirements;
+DROP FUNCTION IF EXISTS prevent_retirement_changes();
+
+DROP TABLE IF EXISTS credit_retirements;
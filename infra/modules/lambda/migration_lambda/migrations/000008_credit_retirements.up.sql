@@ -0,0 +1,30 @@
+-- permanent retirements of purchased credits
+CREATE TABLE credit_retirements (
+    id UUID DEFAULT uuid_generate_v4() NOT NULL,
+    wallet_id UUID NOT NULL,
+    owner_id UUID NOT NULL,
+    purchase_id UUID NOT NULL,
+    carbon_credits_id UUID NOT NULL,
+    vintage_year INT,
+    quantity DECIMAL(10,2) NOT NULL CHECK (quantity > 0),
+    beneficiary_name VARCHAR(255) NOT NULL,
+    reason TEXT,
+    retired_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (wallet_id) REFERENCES credit_wallets(id),
+    FOREIGN KEY (owner_id) REFERENCES users(id),
+    FOREIGN KEY (purchase_id) REFERENCES purchases(id),
+    FOREIGN KEY (carbon_credits_id) REFERENCES carbon_credits(id)
+);
+
+CREATE FUNCTION prevent_retirement_changes() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'credit retirements are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER credit_retirements_immutable
+    BEFORE UPDATE OR DELETE ON credit_retirements
+    FOR EACH ROW EXECUTE FUNCTION prevent_retirement_changes();
+
+CREATE INDEX idx_credit_retirements_owner ON credit_retirements(owner_id);
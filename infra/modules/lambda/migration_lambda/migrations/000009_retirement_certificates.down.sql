@@ -0,0 +1,2 @@
+ALTER TABLE credit_retirements DROP CONSTRAINT IF EXISTS credit_retirements_certificate_id_key;
+ALTER TABLE credit_retirements DROP COLUMN IF EXISTS certificate_id;
@@ -0,0 +1,11 @@
+ALTER TABLE credit_retirements ADD COLUMN certificate_id VARCHAR(32);
+
+-- retirements are immutable, so the trigger is lifted only for the backfill
+ALTER TABLE credit_retirements DISABLE TRIGGER credit_retirements_immutable;
+UPDATE credit_retirements
+SET certificate_id = 'GS-' || EXTRACT(YEAR FROM retired_at)::int || '-' || UPPER(SUBSTRING(REPLACE(id::text, '-', '') FROM 1 FOR 12))
+WHERE certificate_id IS NULL;
+ALTER TABLE credit_retirements ENABLE TRIGGER credit_retirements_immutable;
+
+ALTER TABLE credit_retirements ALTER COLUMN certificate_id SET NOT NULL;
+ALTER TABLE credit_retirements ADD CONSTRAINT credit_retirements_certificate_id_key UNIQUE (certificate_id);
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_credit_listings_wallet;
+
+ALTER TABLE credit_listings
+    DROP COLUMN IF EXISTS quantity,
+    DROP COLUMN IF EXISTS wallet_id;
@@ -0,0 +1,7 @@
+-- resale listings are sourced from a buyer's wallet entry instead of a
+-- seller's batch and hold their remaining quantity themselves
+ALTER TABLE credit_listings
+    ADD COLUMN wallet_id UUID REFERENCES credit_wallets(id),
+    ADD COLUMN quantity DECIMAL(10,2) CHECK (quantity >= 0);
+
+CREATE INDEX idx_credit_listings_wallet ON credit_listings(wallet_id);
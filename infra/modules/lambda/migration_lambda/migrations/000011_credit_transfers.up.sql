@@ -0,0 +1,20 @@
+-- credits moved between owners' wallet entries without a sale
+CREATE TABLE credit_transfers (
+    id UUID DEFAULT uuid_generate_v4() NOT NULL,
+    purchase_id UUID NOT NULL,
+    from_wallet_id UUID NOT NULL,
+    to_wallet_id UUID NOT NULL,
+    from_owner_id UUID NOT NULL,
+    to_owner_id UUID NOT NULL,
+    quantity DECIMAL(10,2) NOT NULL CHECK (quantity > 0),
+    transferred_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id),
+    FOREIGN KEY (purchase_id) REFERENCES purchases(id),
+    FOREIGN KEY (from_wallet_id) REFERENCES credit_wallets(id),
+    FOREIGN KEY (to_wallet_id) REFERENCES credit_wallets(id),
+    FOREIGN KEY (from_owner_id) REFERENCES users(id),
+    FOREIGN KEY (to_owner_id) REFERENCES users(id)
+);
+
+CREATE INDEX idx_credit_transfers_from_owner ON credit_transfers(from_owner_id);
+CREATE INDEX idx_credit_transfers_to_owner ON credit_transfers(to_owner_id);
@@ -0,0 +1,2 @@
+-- price history reads purchases by date
+CREATE INDEX idx_purchases_purchase_date ON purchases(purchase_date);
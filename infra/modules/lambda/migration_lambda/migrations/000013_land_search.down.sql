@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_lands_search_vector;
+DROP TRIGGER IF EXISTS lands_search_vector ON lands;
+DROP FUNCTION IF EXISTS lands_search_vector_update();
+ALTER TABLE lands DROP COLUMN IF EXISTS search_vector;
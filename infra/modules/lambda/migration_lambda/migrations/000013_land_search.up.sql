@@ -0,0 +1,23 @@
+-- full-text search over lands; kept up to date by a trigger because
+-- array_to_string isn't immutable and can't back a generated column
+ALTER TABLE lands ADD COLUMN search_vector TSVECTOR;
+
+CREATE FUNCTION lands_search_vector_update() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector :=
+        setweight(to_tsvector('english', COALESCE(NEW.title, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(NEW.location, '')), 'A') ||
+        setweight(to_tsvector('english', COALESCE(array_to_string(NEW.tree_species, ' '), '')), 'B') ||
+        setweight(to_tsvector('english', COALESCE(NEW.description, '')), 'C');
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER lands_search_vector
+    BEFORE INSERT OR UPDATE OF title, description, location, tree_species ON lands
+    FOR EACH ROW EXECUTE FUNCTION lands_search_vector_update();
+
+-- fire the trigger for existing rows
+UPDATE lands SET title = title;
+
+CREATE INDEX idx_lands_search_vector ON lands USING GIN (search_vector);
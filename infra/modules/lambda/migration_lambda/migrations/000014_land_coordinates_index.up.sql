@@ -0,0 +1,2 @@
+-- radius and bounding-box searches prefilter on coordinates
+CREATE INDEX idx_lands_coordinates ON lands(latitude, longitude);
@@ -0,0 +1,5 @@
+ALTER TABLE credit_listings ALTER COLUMN quantity DROP NOT NULL;
+
+UPDATE credit_listings SET quantity = NULL WHERE wallet_id IS NULL;
+
+ALTER TABLE credit_listings ALTER COLUMN quantity TYPE DECIMAL(10,2);
@@ -0,0 +1,36 @@
+-- primary listings used to offer their whole batch, so one batch could back
+-- several of them. The oldest open listing of each batch (active ones first)
+-- is allocated what is left of the batch; the others are allocated nothing
+-- and go back to draft for the seller to re-allocate.
+ALTER TABLE credit_listings ALTER COLUMN quantity TYPE DECIMAL(12,2);
+
+WITH allocations AS (
+    SELECT id, CASE WHEN rank = 1 THEN credits_available ELSE 0 END AS quantity
+    FROM (
+        SELECT l.id, c.credits_available,
+               ROW_NUMBER() OVER (
+                   PARTITION BY l.carbon_credits_id
+                   ORDER BY l.status = 'active' DESC, l.created_at, l.id
+               ) AS rank
+        FROM credit_listings l
+        JOIN carbon_credits c ON c.id = l.carbon_credits_id
+        WHERE l.wallet_id IS NULL AND l.status IN ('draft', 'active')
+    ) ranked
+)
+UPDATE credit_listings l
+SET quantity = a.quantity,
+    status = CASE WHEN a.quantity = 0 THEN 'draft'::listing_status ELSE l.status END,
+    updated_at = CURRENT_TIMESTAMP
+FROM allocations a
+WHERE l.id = a.id;
+
+-- drafts cannot be bought from, so holds on listings moved back to draft
+-- could never be confirmed
+UPDATE credit_reservations r
+SET status = 'released', updated_at = CURRENT_TIMESTAMP
+FROM credit_listings l
+WHERE r.listing_id = l.id AND l.status = 'draft' AND r.status = 'held';
+
+UPDATE credit_listings SET quantity = 0 WHERE quantity IS NULL;
+
+ALTER TABLE credit_listings ALTER COLUMN quantity SET NOT NULL;
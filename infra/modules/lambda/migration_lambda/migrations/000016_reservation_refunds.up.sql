@@ -0,0 +1,3 @@
+-- set once the payment of a hold that never became a purchase is refunded,
+-- so a redelivered payment webhook does not refund it again
+ALTER TABLE credit_reservations ADD COLUMN refunded_at TIMESTAMP WITH TIME ZONE;
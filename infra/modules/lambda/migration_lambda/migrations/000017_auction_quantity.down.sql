@@ -0,0 +1 @@
+ALTER TABLE credit_auctions DROP COLUMN IF EXISTS quantity;
@@ -0,0 +1,34 @@
+-- auctions set aside a lot from the batch when they are created instead of
+-- selling whatever is left when they settle. Open auctions get what is left
+-- of their batch outside open primary listings, the oldest auction of a batch
+-- first; finished ones record what they sold.
+ALTER TABLE credit_auctions ADD COLUMN quantity DECIMAL(12,2) CHECK (quantity >= 0);
+
+WITH lots AS (
+    SELECT a.id,
+           CASE WHEN ROW_NUMBER() OVER (PARTITION BY a.carbon_credits_id ORDER BY a.created_at, a.id) = 1
+                THEN GREATEST(c.credits_available - COALESCE((
+                    SELECT SUM(l.quantity) FROM credit_listings l
+                    WHERE l.carbon_credits_id = a.carbon_credits_id
+                      AND l.wallet_id IS NULL
+                      AND l.status IN ('draft', 'active')
+                ), 0), 0)
+                ELSE 0
+           END AS quantity
+    FROM credit_auctions a
+    JOIN carbon_credits c ON c.id = a.carbon_credits_id
+    WHERE a.status IN ('pending', 'active')
+)
+UPDATE credit_auctions a
+SET quantity = lots.quantity
+FROM lots
+WHERE a.id = lots.id;
+
+UPDATE credit_auctions a
+SET quantity = p.amount
+FROM purchases p
+WHERE p.auction_id = a.id AND a.quantity IS NULL;
+
+UPDATE credit_auctions SET quantity = 0 WHERE quantity IS NULL;
+
+ALTER TABLE credit_auctions ALTER COLUMN quantity SET NOT NULL;
@@ -0,0 +1,6 @@
+-- enum values cannot be dropped; failed auctions become cancelled instead
+UPDATE credit_auctions SET status = 'cancelled' WHERE status = 'failed';
+
+ALTER TABLE credit_auctions
+    DROP COLUMN IF EXISTS settlement_attempts,
+    DROP COLUMN IF EXISTS settlement_error;
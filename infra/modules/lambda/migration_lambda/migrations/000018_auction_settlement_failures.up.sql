@@ -0,0 +1,7 @@
+-- auctions that keep failing to settle are retried a few times and then
+-- marked failed, so they stop holding up the other due auctions
+ALTER TYPE auction_status ADD VALUE IF NOT EXISTS 'failed';
+
+ALTER TABLE credit_auctions
+    ADD COLUMN settlement_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN settlement_error TEXT;
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_purchases_pending_payment_refunds;
+ALTER TABLE purchases DROP COLUMN IF EXISTS payment_refunded_at;
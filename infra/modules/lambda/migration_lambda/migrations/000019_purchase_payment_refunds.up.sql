@@ -0,0 +1,9 @@
+-- refunded_at marks the credits as reversed; payment_refunded_at is set once
+-- the buyer's money is back, so failed gateway refunds can be retried
+ALTER TABLE purchases ADD COLUMN payment_refunded_at TIMESTAMP WITH TIME ZONE;
+
+-- purchases refunded so far were refunded with the gateway in one step
+UPDATE purchases SET payment_refunded_at = refunded_at WHERE refunded_at IS NOT NULL;
+
+CREATE INDEX idx_purchases_pending_payment_refunds ON purchases (refunded_at)
+    WHERE refunded_at IS NOT NULL AND payment_refunded_at IS NULL AND transaction_hash IS NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE credit_reservations DROP COLUMN IF EXISTS buy_order_id;
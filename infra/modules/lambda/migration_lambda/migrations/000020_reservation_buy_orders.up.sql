@@ -0,0 +1,3 @@
+-- buy order fills hold their credits like a checkout while the buyer is
+-- charged, and the purchase they become belongs to the order
+ALTER TABLE credit_reservations ADD COLUMN buy_order_id UUID REFERENCES buy_orders(id);
@@ -51,3 +51,9 @@ variable "email_identity" {
     description = "The email to verify with SES."
     type        = string
 }
+
+variable "payment_provider" {
+    description = "Payment provider for the market service. The fake keeps payments in memory and takes no real money"
+    type        = string
+    default     = "fake"
+}
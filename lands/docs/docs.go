@@ -16,6 +16,65 @@ const docTemplate = `{
     "basePath": "{{.BasePath}}",
     "paths": {
         "/api/lands": {
+            "get": {
+                "description": "Public lands catalog with optional radius and bounding-box filters; with near, lands are sorted by distance",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Lands"
+                ],
+                "summary": "List lands",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by distance from lat,lon",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "With near, only lands within this many km",
+                        "name": "radiusKm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only lands inside minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Land"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
             "post": {
                 "description": "Creates a new land entry",
                 "consumes": [
@@ -90,26 +149,29 @@ const docTemplate = `{
                 "summary": "Get all lands owned by a user",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
+                        "type": "string",
+                        "description": "nextCursor from the previous page",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Items per page (default: 10)",
+                        "description": "Items per page (default: 10, max: 100)",
                         "name": "limit",
                         "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the total number of lands",
+                        "name": "total",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/main.Land"
-                            }
+                            "$ref": "#/definitions/main.Page-main_Land"
                         }
                     },
                     "400": {
@@ -153,8 +215,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.Land"
                         }
                     },
-                    "401": {
-                        "description": "Unauthorized",
+                    "400": {
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -433,6 +495,25 @@ const docTemplate = `{
                 }
             }
         },
+        "main.Page-main_Land": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.Land"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Pass as cursor to get the next page; null on the last page",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Number of matching rows across all pages, only when requested",
+                    "type": "integer"
+                }
+            }
+        },
         "main.Seller": {
             "type": "object",
             "properties": {
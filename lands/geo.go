@@ -0,0 +1,108 @@
+// Geographic filters copied from the first half of marketplace/geo.go. The
+// services are separate modules with no shared package, so keep the two in
+// sync.
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	earthRadiusKm = 6371.0
+	kmPerDegree   = 111.045
+)
+
+func parseGeoPoint(s string) (*GeoPoint, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected lat,lon")
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("latitude must be between -90 and 90")
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("longitude must be between -180 and 180")
+	}
+
+	return &GeoPoint{Lat: lat, Lon: lon}, nil
+}
+
+// parseBoundingBox reads minLon,minLat,maxLon,maxLat. A box whose minLon is
+// greater than its maxLon crosses the antimeridian.
+func parseBoundingBox(s string) (*BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("expected minLon,minLat,maxLon,maxLat")
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = f
+	}
+
+	box := &BoundingBox{MinLon: v[0], MinLat: v[1], MaxLon: v[2], MaxLat: v[3]}
+	if box.MinLat < -90 || box.MaxLat > 90 || box.MinLat > box.MaxLat {
+		return nil, fmt.Errorf("latitudes must be between -90 and 90 with minLat <= maxLat")
+	}
+	if box.MinLon < -180 || box.MaxLon > 180 {
+		return nil, fmt.Errorf("longitudes must be between -180 and 180")
+	}
+
+	return box, nil
+}
+
+// distanceKm is the haversine great-circle distance in km from the lands row
+// to p.
+func distanceKm(p GeoPoint) clause.Expr {
+	return clause.Expr{
+		SQL: "? * 2 * ASIN(SQRT(POWER(SIN(RADIANS(lands.latitude - ?) / 2), 2) + " +
+			"COS(RADIANS(?)) * COS(RADIANS(lands.latitude)) * POWER(SIN(RADIANS(lands.longitude - ?) / 2), 2)))",
+		Vars: []interface{}{earthRadiusKm, p.Lat, p.Lat, p.Lon},
+	}
+}
+
+// withinRadius keeps lands within km of p. A bounding box around the circle
+// is checked first so the latitude/longitude index can do most of the work.
+func withinRadius(query *gorm.DB, p GeoPoint, km float64) *gorm.DB {
+	dLat := km / kmPerDegree
+	query = query.Where("lands.latitude BETWEEN ? AND ?", p.Lat-dLat, p.Lat+dLat)
+
+	if cos := math.Cos(p.Lat * math.Pi / 180); cos > 0.01 {
+		if dLon := km / (kmPerDegree * cos); dLon < 180 {
+			minLon, maxLon := p.Lon-dLon, p.Lon+dLon
+			switch {
+			case minLon < -180:
+				query = query.Where("lands.longitude >= ? OR lands.longitude <= ?", minLon+360, maxLon)
+			case maxLon > 180:
+				query = query.Where("lands.longitude >= ? OR lands.longitude <= ?", minLon, maxLon-360)
+			default:
+				query = query.Where("lands.longitude BETWEEN ? AND ?", minLon, maxLon)
+			}
+		}
+	}
+
+	d := distanceKm(p)
+	return query.Where(clause.Expr{SQL: d.SQL + " <= ?", Vars: append(d.Vars, km)})
+}
+
+func withinBoundingBox(query *gorm.DB, box BoundingBox) *gorm.DB {
+	query = query.Where("lands.latitude BETWEEN ? AND ?", box.MinLat, box.MaxLat)
+	if box.MinLon > box.MaxLon {
+		return query.Where("lands.longitude >= ? OR lands.longitude <= ?", box.MinLon, box.MaxLon)
+	}
+	return query.Where("lands.longitude BETWEEN ? AND ?", box.MinLon, box.MaxLon)
+}
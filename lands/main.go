@@ -74,6 +74,7 @@ func main() {
 	http.HandleFunc("GET /api/lands/swagger/", httpSwagger.WrapHandler)
 	http.HandleFunc("GET /api/lands/health/{$}", handler.handleHealthCheck)
 	http.HandleFunc("GET /api/lands/user/{id}", handler.handleGetUserLands)
+	http.HandleFunc("GET /api/lands", handler.handleListLands)
 	http.HandleFunc("GET /api/lands/{id}", handler.handleGetLand)
 	http.HandleFunc("POST /api/lands", handler.handleCreateLand)
 	http.HandleFunc("PUT /api/lands/{id}", handler.handleUpdateLand)
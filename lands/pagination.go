@@ -0,0 +1,159 @@
+// Keyset pagination copied from marketplace/pagination.go. The services are
+// separate modules with no shared package, so keep the two files in sync.
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// cursor marks the last row of a page. Value is the row's sort value as
+// Postgres renders it in text, so it compares exactly when cast back. Sort
+// ties the cursor to the ordering it was issued for.
+type cursor struct {
+	Sort  string    `json:"s"`
+	Value *string   `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func encodeCursor(c cursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(token, sort string) (*cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.Sort != sort || c.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// keysetSort describes an ORDER BY of a sort value followed by the row ID
+// ascending, NULL sort values last.
+type keysetSort struct {
+	// identifies the ordering in cursors
+	key string
+	// the value rows are ordered by, and its SQL type
+	expr     clause.Expr
+	exprType string
+	desc     bool
+	// the row ID column used as the tiebreaker
+	idColumn string
+}
+
+func (k keysetSort) orderBy() clause.OrderBy {
+	dir := " ASC NULLS LAST, "
+	if k.desc {
+		dir = " DESC NULLS LAST, "
+	}
+	return clause.OrderBy{Expression: clause.Expr{SQL: k.expr.SQL + dir + k.idColumn, Vars: k.expr.Vars}}
+}
+
+// after restricts query to the rows that follow c in this ordering.
+func (k keysetSort) after(query *gorm.DB, c *cursor) *gorm.DB {
+	expr := "(" + k.expr.SQL + ")"
+
+	if c.Value == nil {
+		vars := append(append([]interface{}{}, k.expr.Vars...), c.ID)
+		return query.Where(clause.Expr{SQL: expr + " IS NULL AND " + k.idColumn + " > ?", Vars: vars})
+	}
+
+	op := " > "
+	if k.desc {
+		op = " < "
+	}
+	value := "CAST(? AS " + k.exprType + ")"
+
+	var vars []interface{}
+	vars = append(vars, k.expr.Vars...)
+	vars = append(vars, *c.Value)
+	vars = append(vars, k.expr.Vars...)
+	vars = append(vars, *c.Value, c.ID)
+	vars = append(vars, k.expr.Vars...)
+
+	return query.Where(clause.Expr{
+		SQL:  "(" + expr + op + value + " OR (" + expr + " = " + value + " AND " + k.idColumn + " > ?) OR " + expr + " IS NULL)",
+		Vars: vars,
+	})
+}
+
+// cursorFor returns the cursor for the row with the given ID, reading its
+// sort value through query, which must be able to evaluate k.expr.
+func (k keysetSort) cursorFor(query *gorm.DB, id uuid.UUID) (string, error) {
+	var value *string
+	if err := query.
+		Select("CAST(("+k.expr.SQL+") AS text)", k.expr.Vars...).
+		Where(k.idColumn+" = ?", id).
+		Limit(1).
+		Row().
+		Scan(&value); err != nil {
+		return "", err
+	}
+
+	return encodeCursor(cursor{Sort: k.key, Value: value, ID: id}), nil
+}
+
+// findPage reads the page of base's rows that follows req.Cursor in sort
+// order, fetching one extra row to tell whether another page follows. id
+// returns a row's ID for the next cursor.
+func findPage[T any](base *gorm.DB, sort keysetSort, req PageRequest, id func(*T) uuid.UUID, preloads ...string) (*Page[T], error) {
+	after, err := decodeCursor(req.Cursor, sort.key)
+	if err != nil {
+		return nil, err
+	}
+
+	base = base.Session(&gorm.Session{})
+	page := &Page[T]{Items: []T{}}
+
+	if req.WithTotal {
+		var total int64
+		if err := base.Count(&total).Error; err != nil {
+			return nil, err
+		}
+		page.Total = &total
+	}
+
+	query := base
+	if after != nil {
+		query = sort.after(query, after)
+	}
+	for _, p := range preloads {
+		query = query.Preload(p)
+	}
+
+	if err := query.Order(sort.orderBy()).Limit(req.Limit + 1).Find(&page.Items).Error; err != nil {
+		return nil, err
+	}
+
+	if len(page.Items) > req.Limit {
+		page.Items = page.Items[:req.Limit]
+		next, err := sort.cursorFor(base, id(&page.Items[req.Limit-1]))
+		if err != nil {
+			return nil, err
+		}
+		page.NextCursor = &next
+	}
+
+	return page, nil
+}
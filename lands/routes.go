@@ -2,6 +2,7 @@ package main
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 	"time"
@@ -55,7 +56,7 @@ func getPaginationParams(r *http.Request) (int, int) {
 	limitStr := r.URL.Query().Get("limit")
 
 	page := 1
-	limit := 10
+	limit := defaultPageLimit
 
 	if pageStr != "" {
 		page, _ = strconv.Atoi(pageStr)
@@ -64,9 +65,78 @@ func getPaginationParams(r *http.Request) (int, int) {
 		limit, _ = strconv.Atoi(limitStr)
 	}
 
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
 	return page, limit
 }
 
+func getPageRequest(r *http.Request) (PageRequest, error) {
+	qs := r.URL.Query()
+
+	req := PageRequest{Cursor: qs.Get("cursor"), Limit: defaultPageLimit}
+
+	if limitStr := qs.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return req, fmt.Errorf("invalid limit: must be a positive integer")
+		}
+		req.Limit = min(limit, maxPageLimit)
+	}
+
+	if totalStr := qs.Get("total"); totalStr != "" {
+		withTotal, err := strconv.ParseBool(totalStr)
+		if err != nil {
+			return req, fmt.Errorf("invalid total: %v", err)
+		}
+		req.WithTotal = withTotal
+	}
+
+	return req, nil
+}
+
+func getLandFilter(r *http.Request) (*LandFilter, error) {
+	qs := r.URL.Query()
+
+	filter := &LandFilter{}
+
+	if nearStr := qs.Get("near"); nearStr != "" {
+		near, err := parseGeoPoint(nearStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid near: %v", err)
+		}
+		filter.Near = near
+	}
+
+	if radiusStr := qs.Get("radiusKm"); radiusStr != "" {
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius <= 0 {
+			return nil, fmt.Errorf("invalid radiusKm: must be a positive number")
+		}
+		if filter.Near == nil {
+			return nil, fmt.Errorf("radiusKm requires near")
+		}
+		filter.RadiusKm = &radius
+	}
+
+	if bboxStr := qs.Get("bbox"); bboxStr != "" {
+		bbox, err := parseBoundingBox(bboxStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox: %v", err)
+		}
+		filter.BBox = bbox
+	}
+
+	return filter, nil
+}
+
 // handleHealthCheck godoc
 // @Summary Check API health
 // @Description Returns OK if the API is running
@@ -150,9 +220,10 @@ func (h *Handler) handleCreateLand(w http.ResponseWriter, r *http.Request) {
 // @Description Retrieves all lands owned by the user specified in id path value
 // @Tags Lands
 // @Produce json
-// @Param page query int false "Page number (default: 1)"
-// @Param limit query int false "Items per page (default: 10)"
-// @Success 200 {array} Land
+// @Param cursor query string false "nextCursor from the previous page"
+// @Param limit query int false "Items per page (default: 10, max: 100)"
+// @Param total query bool false "Include the total number of lands"
+// @Success 200 {object} Page[Land]
 // @Failure 401 {object} ErrorResponse
 // @Failure 400 {object} ErrorResponse
 // @Router /api/lands/my [get]
@@ -168,8 +239,51 @@ func (h *Handler) handleGetUserLands(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	page, err := getPageRequest(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	lands, err := h.svc.GetUserLands(userID, page)
+	if err != nil {
+		if err == ErrInvalidCursor {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(lands)
+}
+
+// handleListLands godoc
+// @Summary List lands
+// @Description Public lands catalog with optional radius and bounding-box filters; with near, lands are sorted by distance
+// @Tags Lands
+// @Produce json
+// @Param page query int false "Page number (default: 1)"
+// @Param limit query int false "Items per page (default: 10)"
+// @Param near query string false "Sort by distance from lat,lon"
+// @Param radiusKm query number false "With near, only lands within this many km"
+// @Param bbox query string false "Only lands inside minLon,minLat,maxLon,maxLat"
+// @Success 200 {array} Land
+// @Failure 400 {object} ErrorResponse
+// @Router /api/lands [get]
+func (h *Handler) handleListLands(w http.ResponseWriter, r *http.Request) {
+	filter, err := getLandFilter(r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
 	page, limit := getPaginationParams(r)
-	lands, err := h.svc.GetUserLands(userID, page, limit)
+	lands, err := h.svc.ListLands(filter, page, limit)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
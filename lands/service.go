@@ -6,6 +6,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type LandSVC struct {
@@ -17,8 +18,9 @@ func NewLandSVC(db *gorm.DB) LandService {
 }
 
 var (
-	ErrUnauthorized = errors.New("unauthorized access")
-	ErrNotFound     = errors.New("land not found")
+	ErrUnauthorized  = errors.New("unauthorized access")
+	ErrNotFound      = errors.New("land not found")
+	ErrInvalidCursor = errors.New("invalid cursor")
 )
 
 func (s *LandSVC) CreateLand(land *Land) error {
@@ -73,22 +75,42 @@ func (s *LandSVC) DeleteLand(id uuid.UUID) error {
 	return nil
 }
 
-func (s *LandSVC) ListLands(page, limit int) ([]Land, error) {
+func (s *LandSVC) ListLands(filter *LandFilter, page, limit int) ([]Land, error) {
 	var lands []Land
 	offset := (page - 1) * limit
-	err := s.db.Offset(offset).Limit(limit).Find(&lands).Error
-	return lands, err
-}
+	query := s.db.Model(&Land{}).Offset(offset).Limit(limit)
 
-func (s *LandSVC) GetUserLands(userID uuid.UUID, page, limit int) ([]Land, error) {
-	var lands []Land
-	offset := (page - 1) * limit
+	if filter != nil {
+		if filter.BBox != nil {
+			query = withinBoundingBox(query, *filter.BBox)
+		}
 
-	err := s.db.Joins("JOIN sellers ON lands.owner_id = sellers.id").
-		Where("sellers.user_id = ?", userID).
-		Offset(offset).
-		Limit(limit).
-		Find(&lands).Error
+		if filter.Near != nil {
+			if filter.RadiusKm != nil {
+				query = withinRadius(query, *filter.Near, *filter.RadiusKm)
+			}
+			d := distanceKm(*filter.Near)
+			query = query.Order(clause.OrderBy{Expression: clause.Expr{SQL: d.SQL + " ASC", Vars: d.Vars}})
+		}
+	}
 
+	// Without a total order, offset pages can skip or repeat lands
+	err := query.Order("lands.id").Find(&lands).Error
 	return lands, err
 }
+
+func (s *LandSVC) GetUserLands(userID uuid.UUID, page PageRequest) (*Page[Land], error) {
+	query := s.db.Model(&Land{}).
+		Joins("JOIN sellers ON lands.owner_id = sellers.id").
+		Where("sellers.user_id = ?", userID)
+
+	sort := keysetSort{
+		key:      "-createdAt",
+		expr:     clause.Expr{SQL: "lands.created_at"},
+		exprType: "timestamptz",
+		desc:     true,
+		idColumn: "lands.id",
+	}
+
+	return findPage(query, sort, page, func(l *Land) uuid.UUID { return l.ID })
+}
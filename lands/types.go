@@ -14,8 +14,44 @@ type LandService interface {
 	GetLand(id uuid.UUID) (*Land, error)
 	UpdateLand(land *Land) error
 	DeleteLand(id uuid.UUID) error
-	ListLands(page, limit int) ([]Land, error)
-	GetUserLands(userID uuid.UUID, page, limit int) ([]Land, error)
+	ListLands(filter *LandFilter, page, limit int) ([]Land, error)
+	GetUserLands(userID uuid.UUID, page PageRequest) (*Page[Land], error)
+}
+
+// PageRequest asks for the page after Cursor, or the first page when Cursor
+// is empty.
+type PageRequest struct {
+	Cursor    string
+	Limit     int
+	WithTotal bool
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Pass as cursor to get the next page; null on the last page
+	NextCursor *string `json:"nextCursor"`
+	// Number of matching rows across all pages, only when requested
+	Total *int64 `json:"total,omitempty"`
+}
+
+// LandFilter narrows the public lands catalog. Near sorts lands by distance
+// from a point, and with RadiusKm also limits them to that radius.
+type LandFilter struct {
+	Near     *GeoPoint
+	RadiusKm *float64
+	BBox     *BoundingBox
+}
+
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type BoundingBox struct {
+	MinLon float64 `json:"minLon"`
+	MinLat float64 `json:"minLat"`
+	MaxLon float64 `json:"maxLon"`
+	MaxLat float64 `json:"maxLat"`
 }
 
 type LandRequest struct {
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/go-pdf/fpdf"
+)
+
+// writeRetirementCertificate renders a one page PDF certificate for a
+// retirement. The retirement must have CarbonCredit.Land preloaded.
+func writeRetirementCertificate(w io.Writer, retirement *CreditRetirement) error {
+	credit := retirement.CarbonCredit
+	land := credit.Land
+
+	vintage := "n/a"
+	if retirement.VintageYear != nil {
+		vintage = strconv.Itoa(*retirement.VintageYear)
+	}
+	standard := "n/a"
+	if credit.VerificationStandard != nil {
+		standard = *credit.VerificationStandard
+	}
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("GreenSquare Retirement Certificate "+retirement.CertificateID, true)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+
+	pdf.SetFont("Helvetica", "B", 22)
+	pdf.CellFormat(0, 14, "Carbon Credit Retirement Certificate", "", 1, "C", false, 0, "")
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.CellFormat(0, 8, "GreenSquare Marketplace", "", 1, "C", false, 0, "")
+	pdf.Ln(10)
+
+	pdf.SetFont("Helvetica", "", 12)
+	pdf.MultiCell(0, 7, tr(fmt.Sprintf(
+		"This certifies that %s carbon credits were permanently retired on behalf of %s on %s.",
+		strconv.FormatFloat(retirement.Quantity, 'f', 2, 64),
+		retirement.BeneficiaryName,
+		retirement.RetiredAt.UTC().Format("2 January 2006"),
+	)), "", "L", false)
+	pdf.Ln(6)
+
+	rows := [][2]string{
+		{"Certificate ID", retirement.CertificateID},
+		{"Land", land.Title},
+		{"Location", land.Location},
+		{"Biome", land.BiomeType},
+		{"Vintage year", vintage},
+		{"Verification standard", standard},
+		{"Quantity (tCO2e)", strconv.FormatFloat(retirement.Quantity, 'f', 2, 64)},
+		{"Beneficiary", retirement.BeneficiaryName},
+	}
+	if retirement.Reason != nil && *retirement.Reason != "" {
+		rows = append(rows, [2]string{"Reason", *retirement.Reason})
+	}
+
+	for _, row := range rows {
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(55, 8, row[0], "B", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 11)
+		pdf.MultiCell(0, 8, tr(row[1]), "B", "L", false)
+	}
+
+	pdf.Ln(10)
+	pdf.SetFont("Helvetica", "I", 9)
+	pdf.MultiCell(0, 5, "Anyone can confirm this certificate is genuine at /api/market/certificates/"+retirement.CertificateID, "", "L", false)
+
+	return pdf.Output(w)
+}
@@ -30,17 +30,23 @@ const docTemplate = `{
                 "summary": "Get active listings",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
+                        "type": "string",
+                        "description": "nextCursor from the previous page",
+                        "name": "cursor",
                         "in": "query"
                     },
                     {
                         "type": "integer",
-                        "description": "Number of items per page (default: 10)",
+                        "description": "Number of items per page (default: 10, max: 100)",
                         "name": "limit",
                         "in": "query"
                     },
+                    {
+                        "type": "boolean",
+                        "description": "Include the total number of matching listings",
+                        "name": "total",
+                        "in": "query"
+                    },
                     {
                         "type": "number",
                         "description": "Minimum price filter",
@@ -53,21 +59,112 @@ const docTemplate = `{
                         "name": "maxPrice",
                         "in": "query"
                     },
+                    {
+                        "type": "string",
+                        "description": "Biome type filter",
+                        "name": "biomeType",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
                         "description": "Location filter",
                         "name": "location",
                         "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum credits available",
+                        "name": "minCredits",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum credits available",
+                        "name": "maxCredits",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Earliest vintage year",
+                        "name": "minVintageYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Latest vintage year",
+                        "name": "maxVintageYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Verification standard filter",
+                        "name": "verificationStandard",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only credits that don't expire before this date (YYYY-MM-DD)",
+                        "name": "expiresAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Land verification status: pending, verified or rejected",
+                        "name": "landVerificationStatus",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum forest density percentage",
+                        "name": "minForestDensity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Any of these tree species",
+                        "name": "treeSpecies",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search over land title, description, location and tree species; results are ranked by relevance",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by distance from lat,lon",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "With near, only listings within this many km",
+                        "name": "radiusKm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only listings inside minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Sort by price, createdAt, vintage, available or distance (requires near); prefix with - for descending",
+                        "name": "sort",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/main.Land"
-                            }
+                            "$ref": "#/definitions/main.Page-main_CreditListing"
                         }
                     },
                     "400": {
@@ -147,83 +244,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/market/health": {
-            "get": {
-                "description": "Returns OK if the API is running",
-                "tags": [
-                    "Health"
-                ],
-                "summary": "Check API health",
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "string"
-                        }
-                    }
-                }
-            }
-        },
-        "/api/market/private": {
-            "get": {
-                "description": "Retrieves a paginated list of listings belonging to the authenticated seller",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "listings"
-                ],
-                "summary": "Get seller's private listings",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Bearer token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Page number (default: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Number of items per page (default: 10)",
-                        "name": "limit",
-                        "in": "query"
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/main.Land"
-                            }
-                        }
-                    },
-                    "401": {
-                        "description": "Unauthorized - Invalid or missing seller credentials",
-                        "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal server error",
-                        "schema": {
-                            "$ref": "#/definitions/main.ErrorResponse"
-                        }
-                    }
-                }
-            },
+        "/api/market/active/{id}/orders": {
             "post": {
-                "description": "Creates a new credit listing for a seller's carbon credits",
+                "description": "Charges the buyer and purchases an amount of credits from an active listing, adding them to the buyer's wallet",
                 "consumes": [
                     "application/json"
                 ],
@@ -231,9 +254,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "listings"
+                    "orders"
                 ],
-                "summary": "Create a new credit listing",
+                "summary": "Buy credits from an active listing",
                 "parameters": [
                     {
                         "type": "string",
@@ -244,26 +267,34 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "User Role (must be 'seller')",
+                        "description": "User Role (must be 'buyer')",
                         "name": "X-User-Role",
                         "in": "header",
                         "required": true
                     },
                     {
-                        "description": "Listing creation request",
-                        "name": "listing",
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Listing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Order request",
+                        "name": "order",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.CreateListingRequest"
+                            "$ref": "#/definitions/main.PlaceOrderRequest"
                         }
                     }
                 ],
                 "responses": {
                     "201": {
-                        "description": "Created listing",
+                        "description": "Created purchase",
                         "schema": {
-                            "$ref": "#/definitions/main.CreditListing"
+                            "$ref": "#/definitions/main.Purchase"
                         }
                     },
                     "400": {
@@ -278,85 +309,36 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "500": {
-                        "description": "Internal server error",
+                    "402": {
+                        "description": "Payment failed",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
-                    }
-                }
-            }
-        },
-        "/api/market/private/{id}": {
-            "get": {
-                "description": "Retrieves a specific listing belonging to the authenticated seller",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "listings"
-                ],
-                "summary": "Get seller's private listing by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "Bearer token",
-                        "name": "Authorization",
-                        "in": "header",
-                        "required": true
-                    },
-                    {
-                        "type": "string",
-                        "description": "Listing ID (UUID format)",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "OK",
-                        "schema": {
-                            "$ref": "#/definitions/main.Land"
-                        }
-                    },
-                    "400": {
-                        "description": "Invalid listing ID",
-                        "schema": {
-                            "type": "string"
-                        }
                     },
-                    "401": {
-                        "description": "Unauthorized - Invalid or missing seller credentials",
+                    "404": {
+                        "description": "Listing not found",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
-                    "404": {
-                        "description": "Listing not found",
+                    "409": {
+                        "description": "Not enough credits available, or the land or seller is unverified or the credits expired",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "type": "string"
-                        }
-                    },
-                    "504": {
-                        "description": "Request timed out",
-                        "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/main.ErrorResponse"
                         }
                     }
                 }
-            },
-            "put": {
-                "description": "Updates an existing credit listing owned by the seller",
+            }
+        },
+        "/api/market/active/{id}/reservations": {
+            "post": {
+                "description": "Holds an amount of a listing's credits at the current price for the configured TTL, so no one else can buy them while the buyer pays",
                 "consumes": [
                     "application/json"
                 ],
@@ -364,9 +346,9 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "listings"
+                    "orders"
                 ],
-                "summary": "Update an existing credit listing",
+                "summary": "Hold credits for checkout",
                 "parameters": [
                     {
                         "type": "string",
@@ -377,7 +359,7 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "User Role (must be 'seller')",
+                        "description": "User Role (must be 'buyer')",
                         "name": "X-User-Role",
                         "in": "header",
                         "required": true
@@ -391,20 +373,20 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Listing update request",
-                        "name": "listing",
+                        "description": "Reservation request",
+                        "name": "reservation",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/main.UpdateListingRequest"
+                            "$ref": "#/definitions/main.ReserveCreditsRequest"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "Updated listing",
+                    "201": {
+                        "description": "Created reservation",
                         "schema": {
-                            "$ref": "#/definitions/main.CreditListing"
+                            "$ref": "#/definitions/main.CreditReservation"
                         }
                     },
                     "400": {
@@ -425,6 +407,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
                     },
+                    "409": {
+                        "description": "Not enough credits available, or the land or seller is unverified or the credits expired",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
@@ -432,27 +420,2019 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Deletes an existing credit listing owned by the seller",
+            }
+        },
+        "/api/market/auctions": {
+            "get": {
+                "description": "Retrieves a paginated list of auctions currently open for bidding, ending soonest first",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "listings"
+                    "auctions"
                 ],
-                "summary": "Delete a credit listing",
+                "summary": "Get active auctions",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "X-User-ID",
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum starting price filter",
+                        "name": "minPrice",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum starting price filter",
+                        "name": "maxPrice",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Biome type filter",
+                        "name": "biomeType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Location filter",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum credits available",
+                        "name": "minCredits",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Maximum credits available",
+                        "name": "maxCredits",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Earliest vintage year",
+                        "name": "minVintageYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Latest vintage year",
+                        "name": "maxVintageYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Verification standard filter",
+                        "name": "verificationStandard",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only credits that don't expire before this date (YYYY-MM-DD)",
+                        "name": "expiresAfter",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Land verification status: pending, verified or rejected",
+                        "name": "landVerificationStatus",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "Minimum forest density percentage",
+                        "name": "minForestDensity",
+                        "in": "query"
+                    },
+                    {
+                        "type": "array",
+                        "items": {
+                            "type": "string"
+                        },
+                        "collectionFormat": "multi",
+                        "description": "Any of these tree species",
+                        "name": "treeSpecies",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Full-text search over land title, description, location and tree species",
+                        "name": "q",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Centre point lat,lon for radiusKm",
+                        "name": "near",
+                        "in": "query"
+                    },
+                    {
+                        "type": "number",
+                        "description": "With near, only auctions within this many km",
+                        "name": "radiusKm",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only auctions inside minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.CreditAuction"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid filters on request, including any sort",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Request timed out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates an auction for a seller's carbon credits, setting the lot quantity aside from the batch",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auctions"
+                ],
+                "summary": "Create a new credit auction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'seller')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Auction creation request",
+                        "name": "auction",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateAuctionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created auction",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditAuction"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough unallocated credits in the batch, or land or seller unverified or credits expired",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/auctions/{id}": {
+            "get": {
+                "description": "Retrieves an auction and its bids, highest first. Bids on sealed auctions are hidden until settlement",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auctions"
+                ],
+                "summary": "Get auction by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Auction ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditAuction"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid auction ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "Auction not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Request timed out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/auctions/{id}/bids": {
+            "post": {
+                "description": "Places a bid per credit. English auctions need the starting price or the highest bid plus the minimum increment, sealed auctions the starting price, and Dutch auctions are won outright by a bid at or above the current price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auctions"
+                ],
+                "summary": "Place a bid on an auction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Auction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Bid request",
+                        "name": "bid",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.PlaceBidRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created bid",
+                        "schema": {
+                            "$ref": "#/definitions/main.AuctionBid"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or bid too low",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment for a Dutch auction lot failed",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Auction not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Auction is not open for bidding, or a Dutch auction lot's land or seller is unverified or its credits expired",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/auctions/{id}/proxy": {
+            "put": {
+                "description": "Stores the bidder's maximum bid; the service then bids on their behalf by the auction's minimum increment up to that cap",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "auctions"
+                ],
+                "summary": "Set a proxy bid on an auction",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Auction ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Proxy bid request",
+                        "name": "proxy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.ProxyBidRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Stored proxy bid",
+                        "schema": {
+                            "$ref": "#/definitions/main.AuctionProxyBid"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request or maximum too low",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Auction not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Auction is not open for bidding or not an English auction",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/certificates/{certificateId}": {
+            "get": {
+                "description": "Public endpoint confirming that a certificate ID belongs to a genuine retirement",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "certificates"
+                ],
+                "summary": "Verify a retirement certificate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Certificate ID",
+                        "name": "certificateId",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.CertificateVerification"
+                        }
+                    },
+                    "404": {
+                        "description": "Certificate not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/health": {
+            "get": {
+                "description": "Returns OK if the API is running",
+                "tags": [
+                    "Health"
+                ],
+                "summary": "Check API health",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/lands/geojson": {
+            "get": {
+                "description": "Returns lands as a GeoJSON FeatureCollection of points with land properties and the best current price per credit, for GIS tools such as QGIS",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "lands"
+                ],
+                "summary": "Export lands as GeoJSON",
+                "parameters": [
+                    {
+                        "type": "boolean",
+                        "description": "Only lands with at least one active listing",
+                        "name": "activeOnly",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only lands inside minLon,minLat,maxLon,maxLat",
+                        "name": "bbox",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.LandFeatureCollection"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Request timed out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/payments/webhook": {
+            "post": {
+                "description": "Applies asynchronous payment outcomes to checkout reservations. The request must carry the provider's signature",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "payments"
+                ],
+                "summary": "Receive payment provider webhooks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Provider signature of the raw body",
+                        "name": "X-Payment-Signature",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid payload",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Invalid signature",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private": {
+            "get": {
+                "description": "Retrieves a paginated list of listings belonging to the authenticated seller",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Get seller's private listings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "nextCursor from the previous page",
+                        "name": "cursor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default: 10, max: 100)",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Include the total number of listings",
+                        "name": "total",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Page-main_CreditListing"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid pagination parameters",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing seller credentials",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a new credit listing for a seller's carbon credits, allocating the given quantity from the batch",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Create a new credit listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'seller')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Listing creation request",
+                        "name": "listing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateListingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created listing",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditListing"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough unallocated credits in the batch, or an active listing's land or seller is unverified or its credits expired",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/buy-orders": {
+            "get": {
+                "description": "Retrieves a paginated list of the authenticated buyer's buy orders, newest first",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Get buyer's buy orders",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number (default: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of items per page (default: 10)",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.BuyOrder"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Creates a limit order that the matching engine fills against active listings at or below the maximum price",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Create a standing buy order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Buy order request",
+                        "name": "order",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateBuyOrderRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created buy order",
+                        "schema": {
+                            "$ref": "#/definitions/main.BuyOrder"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/buy-orders/{id}": {
+            "delete": {
+                "description": "Cancels the unfilled remainder of an open buy order; fills already made are kept",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Cancel a buy order",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Buy order ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Buy order not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/credits/{id}/availability": {
+            "get": {
+                "description": "Checks whether the given amount of one of the seller's credit batches is still free to allocate to a listing or auction",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Check unallocated credits",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'seller')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Carbon credit batch ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "number",
+                        "description": "Credits to allocate",
+                        "name": "amount",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Credits are available"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Credit batch not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough unallocated credits",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/lands/{id}/verification": {
+            "get": {
+                "description": "Checks that one of the seller's lands and the seller are both verified, so credits from it can be listed",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Check whether a land can be listed",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'seller')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Land ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Land and seller are verified"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Land not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Land or seller is not verified",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/retirements/{id}/certificate": {
+            "get": {
+                "description": "Renders the PDF certificate for one of the authenticated buyer's retirements",
+                "produces": [
+                    "application/pdf"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Download a retirement certificate",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Retirement ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "PDF certificate",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Retirement not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/wallets": {
+            "get": {
+                "description": "Lists the authenticated buyer's credits grouped by vintage year, biome and originating land, with purchased, remaining and retired quantities and the average acquisition price",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Get buyer's wallet holdings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.WalletHolding"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/wallets/{id}/listings": {
+            "post": {
+                "description": "Puts credits from one of the authenticated buyer's wallet entries up for sale on the secondary market. The listed quantity is reserved from the entry's remaining credits until it sells or the listing is cancelled",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Relist credits from a wallet entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Wallet entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Resale listing request",
+                        "name": "listing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.CreateResaleListingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created resale listing",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditListing"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough credits remaining, land or seller unverified, or credits expired",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/wallets/{id}/retire": {
+            "post": {
+                "description": "Permanently retires credits on behalf of a beneficiary and records an immutable retirement tied to the originating purchase and vintage",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Retire credits from a wallet entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Wallet entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Retirement request",
+                        "name": "retirement",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.RetireCreditsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created retirement",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditRetirement"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet entry not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough credits remaining",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/wallets/{id}/transfers": {
+            "post": {
+                "description": "Moves credits from one of the authenticated buyer's wallet entries to a new wallet entry of another buyer without a sale, keeping the link to the original purchase. Retired or resale-reserved credits cannot be transferred",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Transfer credits to another buyer",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Wallet entry ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Transfer request",
+                        "name": "transfer",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.TransferCreditsRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created transfer",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditTransfer"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Wallet entry or recipient not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough credits remaining",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/{id}": {
+            "get": {
+                "description": "Retrieves a specific listing belonging to the authenticated seller",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Get seller's private listing by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Listing ID (UUID format)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.Land"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid listing ID",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized - Invalid or missing seller credentials",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Listing not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Request timed out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Updates an existing credit listing owned by the seller",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Update an existing credit listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'seller')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Listing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Listing update request",
+                        "name": "listing",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.UpdateListingRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Updated listing",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditListing"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Listing not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Status transition not allowed, listing is sold or cancelled, not enough unallocated credits in the batch, or land or seller unverified or credits expired on activation",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Deletes one of the seller's draft listings that was never published. Published listings have to be cancelled instead",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Delete a draft listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'seller')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Listing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Listing not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Listing is not an unpublished draft",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/{id}/cancel": {
+            "post": {
+                "description": "Withdraws one of the seller's draft or active listings and releases any credits held on it",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Cancel a listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'seller')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Listing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Cancelled listing",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditListing"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Listing not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Listing is already sold or cancelled",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/private/{id}/publish": {
+            "post": {
+                "description": "Moves one of the seller's draft listings to active",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "listings"
+                ],
+                "summary": "Publish a draft listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'seller')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Listing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Published listing",
+                        "schema": {
+                            "$ref": "#/definitions/main.CreditListing"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Listing not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Listing is not a draft, has no quantity allocated, its land or seller is unverified or its credits expired",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/purchases/{id}/refund": {
+            "post": {
+                "description": "Reverses a purchase within the refund grace period, returning the credits to the market and refunding the payment. If the gateway refund fails it is retried in the background until PaymentRefundedAt is set. Refused once any of the credits were retired or transferred",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Cancel a purchase for a refund",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Purchase ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Refunded purchase",
+                        "schema": {
+                            "$ref": "#/definitions/main.Purchase"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Purchase not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Grace period passed or credits already used",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/resale/{id}": {
+            "delete": {
+                "description": "Withdraws one of the authenticated buyer's resale listings and returns its unsold credits to the wallet entry they came from",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "wallet"
+                ],
+                "summary": "Cancel a resale listing",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Listing ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "No Content"
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Listing not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/reservations/{id}": {
+            "delete": {
+                "description": "Gives held credits back to the market before the hold expires",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Release a checkout reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
                         "in": "header",
                         "required": true
                     },
                     {
                         "type": "string",
-                        "description": "User Role (must be 'seller')",
+                        "description": "User Role (must be 'buyer')",
                         "name": "X-User-Role",
                         "in": "header",
                         "required": true
@@ -460,7 +2440,7 @@ const docTemplate = `{
                     {
                         "type": "string",
                         "format": "uuid",
-                        "description": "Listing ID",
+                        "description": "Reservation ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -483,7 +2463,93 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "Listing not found",
+                        "description": "Reservation not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/reservations/{id}/confirm": {
+            "post": {
+                "description": "Confirms the hold's payment intent and turns the hold into a purchase at the reserved price",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "orders"
+                ],
+                "summary": "Confirm a checkout reservation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "X-User-ID",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User Role (must be 'buyer')",
+                        "name": "X-User-Role",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "uuid",
+                        "description": "Reservation ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created purchase",
+                        "schema": {
+                            "$ref": "#/definitions/main.Purchase"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "402": {
+                        "description": "Payment failed",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Reservation or listing not found",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "Not enough credits available",
+                        "schema": {
+                            "$ref": "#/definitions/main.ErrorResponse"
+                        }
+                    },
+                    "410": {
+                        "description": "Reservation has expired",
                         "schema": {
                             "$ref": "#/definitions/main.ErrorResponse"
                         }
@@ -496,9 +2562,250 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
+        },
+        "/api/market/stats/candles": {
+            "get": {
+                "description": "Returns open/high/low/close/volume buckets built from completed purchases, oldest first, with optional filters",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get price candles",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bucket size: day, week or month (default: day)",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Biome type filter",
+                        "name": "biomeType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Location filter",
+                        "name": "location",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Vintage year filter",
+                        "name": "vintageYear",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Verification standard filter",
+                        "name": "verificationStandard",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only purchases at or after this time (RFC 3339)",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Only purchases before this time (RFC 3339)",
+                        "name": "to",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/main.Candle"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid query parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Request timed out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/market/stats/summary": {
+            "get": {
+                "description": "Returns marketplace totals for the dashboard: active listings, credits on offer, traded volume and VWAP over the last 24 hours, 7 days and 30 days, and the best ask per biome. The summary is cached briefly",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Get market summary",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/main.MarketSummary"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "504": {
+                        "description": "Request timed out",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "main.AuctionBid": {
+            "type": "object",
+            "properties": {
+                "auctionID": {
+                    "type": "string"
+                },
+                "automatic": {
+                    "type": "boolean"
+                },
+                "bidAmount": {
+                    "type": "number"
+                },
+                "bidTime": {
+                    "type": "string"
+                },
+                "bidderID": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.AuctionProxyBid": {
+            "type": "object",
+            "properties": {
+                "auctionID": {
+                    "type": "string"
+                },
+                "bidderID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "maxAmount": {
+                    "type": "number"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.BiomeAsk": {
+            "type": "object",
+            "properties": {
+                "biomeType": {
+                    "type": "string"
+                },
+                "pricePerCredit": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.BuyOrder": {
+            "type": "object",
+            "properties": {
+                "biomeType": {
+                    "type": "string"
+                },
+                "buyerID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "maxPricePerCredit": {
+                    "type": "number"
+                },
+                "minVintageYear": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "quantityFilled": {
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "verificationStandard": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.Candle": {
+            "type": "object",
+            "properties": {
+                "bucket": {
+                    "type": "string"
+                },
+                "close": {
+                    "type": "number"
+                },
+                "high": {
+                    "type": "number"
+                },
+                "low": {
+                    "type": "number"
+                },
+                "open": {
+                    "type": "number"
+                },
+                "trades": {
+                    "type": "integer"
+                },
+                "volume": {
+                    "type": "number"
+                }
+            }
+        },
         "main.CarbonCredit": {
             "type": "object",
             "properties": {
@@ -548,29 +2855,308 @@ const docTemplate = `{
                 }
             }
         },
+        "main.CertificateVerification": {
+            "type": "object",
+            "properties": {
+                "beneficiaryName": {
+                    "type": "string"
+                },
+                "certificateId": {
+                    "type": "string"
+                },
+                "landTitle": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "retiredAt": {
+                    "type": "string"
+                },
+                "valid": {
+                    "type": "boolean"
+                },
+                "verificationStandard": {
+                    "type": "string"
+                },
+                "vintageYear": {
+                    "type": "integer"
+                }
+            }
+        },
+        "main.CreateAuctionRequest": {
+            "type": "object",
+            "properties": {
+                "auctionType": {
+                    "description": "english (default), sealed_second_price or dutch",
+                    "type": "string"
+                },
+                "carbonCreditsId": {
+                    "type": "string"
+                },
+                "endTime": {
+                    "type": "string"
+                },
+                "minIncrement": {
+                    "description": "Bid step for English auctions, price drop per interval for Dutch ones",
+                    "type": "number"
+                },
+                "priceDropIntervalSeconds": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Credits set aside from the batch and sold to the winner",
+                    "type": "number"
+                },
+                "reservePrice": {
+                    "type": "number"
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "startingPrice": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.CreateBuyOrderRequest": {
+            "type": "object",
+            "properties": {
+                "biomeType": {
+                    "type": "string"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "maxPricePerCredit": {
+                    "type": "number"
+                },
+                "minVintageYear": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "verificationStandard": {
+                    "type": "string"
+                }
+            }
+        },
         "main.CreateListingRequest": {
             "type": "object",
             "properties": {
                 "carbonCreditsId": {
                     "type": "string"
                 },
-                "maximumPurchase": {
-                    "type": "number"
+                "maximumPurchase": {
+                    "type": "number"
+                },
+                "minimumPurchase": {
+                    "type": "number"
+                },
+                "pricePerCredit": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "Credits allocated from the batch to this listing",
+                    "type": "number"
+                },
+                "status": {
+                    "description": "draft (default) or active",
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreateResaleListingRequest": {
+            "type": "object",
+            "properties": {
+                "maximumPurchase": {
+                    "type": "number"
+                },
+                "minimumPurchase": {
+                    "type": "number"
+                },
+                "pricePerCredit": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.CreditAuction": {
+            "type": "object",
+            "properties": {
+                "auctionType": {
+                    "type": "string"
+                },
+                "bids": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.AuctionBid"
+                    }
+                },
+                "carbonCredit": {
+                    "description": "Relationships",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.CarbonCredit"
+                        }
+                    ]
+                },
+                "carbonCreditsID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "currentPrice": {
+                    "description": "Current asking price of a Dutch auction, computed on read",
+                    "type": "number"
+                },
+                "endTime": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "minIncrement": {
+                    "type": "number"
+                },
+                "priceDropIntervalSeconds": {
+                    "type": "integer"
+                },
+                "quantity": {
+                    "description": "Credits set aside from the batch for the winner",
+                    "type": "number"
+                },
+                "reservePrice": {
+                    "type": "number"
+                },
+                "settlementAttempts": {
+                    "description": "Failed settlement attempts and the last error, for operators",
+                    "type": "integer"
+                },
+                "settlementError": {
+                    "type": "string"
+                },
+                "startTime": {
+                    "type": "string"
+                },
+                "startingPrice": {
+                    "type": "number"
+                },
+                "status": {
+                    "description": "pending, active, completed, cancelled, or failed once settlement gave up",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreditListing": {
+            "type": "object",
+            "properties": {
+                "carbonCredit": {
+                    "description": "Relationships",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.CarbonCredit"
+                        }
+                    ]
+                },
+                "carbonCreditsID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "maximumPurchase": {
+                    "type": "number"
+                },
+                "minimumPurchase": {
+                    "type": "number"
+                },
+                "pricePerCredit": {
+                    "type": "number"
+                },
+                "quantity": {
+                    "description": "How many credits are still offered: allocated from the batch, or on\nresale listings reserved from the buyer wallet entry set in WalletID",
+                    "type": "number"
+                },
+                "status": {
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "walletID": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreditReservation": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "buyOrderID": {
+                    "description": "Set on holds a buy order fill takes while its buyer is charged",
+                    "type": "string"
+                },
+                "buyerID": {
+                    "type": "string"
+                },
+                "carbonCreditsID": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "expiresAt": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "listingID": {
+                    "type": "string"
                 },
-                "minimumPurchase": {
-                    "type": "number"
+                "paymentIntentID": {
+                    "type": "string"
                 },
                 "pricePerCredit": {
                     "type": "number"
                 },
+                "purchaseID": {
+                    "type": "string"
+                },
+                "refundedAt": {
+                    "description": "Set when the hold was paid but never became a purchase and the\npayment was refunded",
+                    "type": "string"
+                },
                 "status": {
                     "type": "string"
+                },
+                "updatedAt": {
+                    "type": "string"
                 }
             }
         },
-        "main.CreditListing": {
+        "main.CreditRetirement": {
             "type": "object",
             "properties": {
+                "beneficiaryName": {
+                    "type": "string"
+                },
                 "carbonCredit": {
                     "description": "Relationships",
                     "allOf": [
@@ -582,25 +3168,60 @@ const docTemplate = `{
                 "carbonCreditsID": {
                     "type": "string"
                 },
-                "createdAt": {
+                "certificateID": {
                     "type": "string"
                 },
                 "id": {
                     "type": "string"
                 },
-                "maximumPurchase": {
-                    "type": "number"
+                "ownerID": {
+                    "type": "string"
                 },
-                "minimumPurchase": {
+                "purchaseID": {
+                    "type": "string"
+                },
+                "quantity": {
                     "type": "number"
                 },
-                "pricePerCredit": {
+                "reason": {
+                    "type": "string"
+                },
+                "retiredAt": {
+                    "type": "string"
+                },
+                "vintageYear": {
+                    "type": "integer"
+                },
+                "walletID": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.CreditTransfer": {
+            "type": "object",
+            "properties": {
+                "fromOwnerID": {
+                    "type": "string"
+                },
+                "fromWalletID": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "purchaseID": {
+                    "type": "string"
+                },
+                "quantity": {
                     "type": "number"
                 },
-                "status": {
+                "toOwnerID": {
                     "type": "string"
                 },
-                "updatedAt": {
+                "toWalletID": {
+                    "type": "string"
+                },
+                "transferredAt": {
                     "type": "string"
                 }
             }
@@ -695,6 +3316,232 @@ const docTemplate = `{
                 }
             }
         },
+        "main.LandFeature": {
+            "type": "object",
+            "properties": {
+                "geometry": {
+                    "$ref": "#/definitions/main.PointGeometry"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "properties": {
+                    "$ref": "#/definitions/main.LandFeatureProperties"
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Feature"
+                }
+            }
+        },
+        "main.LandFeatureCollection": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.LandFeature"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "FeatureCollection"
+                }
+            }
+        },
+        "main.LandFeatureProperties": {
+            "type": "object",
+            "properties": {
+                "activeListings": {
+                    "type": "integer"
+                },
+                "bestPricePerCredit": {
+                    "description": "Lowest price per credit across the land's active listings, null when\nnothing is listed",
+                    "type": "number"
+                },
+                "biomeType": {
+                    "type": "string"
+                },
+                "forestDensityPercentage": {
+                    "type": "number"
+                },
+                "location": {
+                    "type": "string"
+                },
+                "sizeSquareMeters": {
+                    "type": "number"
+                },
+                "title": {
+                    "type": "string"
+                },
+                "verificationStatus": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.MarketSummary": {
+            "type": "object",
+            "properties": {
+                "activeListings": {
+                    "type": "integer"
+                },
+                "bestAsks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.BiomeAsk"
+                    }
+                },
+                "creditsOnOffer": {
+                    "type": "number"
+                },
+                "generatedAt": {
+                    "type": "string"
+                },
+                "last24h": {
+                    "$ref": "#/definitions/main.TradingVolume"
+                },
+                "last30d": {
+                    "$ref": "#/definitions/main.TradingVolume"
+                },
+                "last7d": {
+                    "$ref": "#/definitions/main.TradingVolume"
+                }
+            }
+        },
+        "main.Page-main_CreditListing": {
+            "type": "object",
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/main.CreditListing"
+                    }
+                },
+                "nextCursor": {
+                    "description": "Pass as cursor to get the next page; null on the last page",
+                    "type": "string"
+                },
+                "total": {
+                    "description": "Number of matching rows across all pages, only when requested",
+                    "type": "integer"
+                }
+            }
+        },
+        "main.PlaceBidRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.PlaceOrderRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.PointGeometry": {
+            "type": "object",
+            "properties": {
+                "coordinates": {
+                    "description": "Longitude, latitude as GeoJSON orders them",
+                    "type": "array",
+                    "items": {
+                        "type": "number"
+                    }
+                },
+                "type": {
+                    "type": "string",
+                    "example": "Point"
+                }
+            }
+        },
+        "main.ProxyBidRequest": {
+            "type": "object",
+            "properties": {
+                "maxAmount": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.Purchase": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                },
+                "auctionID": {
+                    "type": "string"
+                },
+                "buyOrderID": {
+                    "type": "string"
+                },
+                "buyerID": {
+                    "type": "string"
+                },
+                "carbonCredit": {
+                    "description": "Relationships",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/main.CarbonCredit"
+                        }
+                    ]
+                },
+                "carbonCreditsID": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "listingID": {
+                    "type": "string"
+                },
+                "paymentRefundedAt": {
+                    "description": "Set once the payment is back with the buyer; a refunded purchase\nwithout it is waiting for a gateway refund to be retried",
+                    "type": "string"
+                },
+                "pricePerCredit": {
+                    "type": "number"
+                },
+                "purchaseDate": {
+                    "type": "string"
+                },
+                "refundedAt": {
+                    "type": "string"
+                },
+                "totalPrice": {
+                    "type": "number"
+                },
+                "transactionHash": {
+                    "type": "string"
+                }
+            }
+        },
+        "main.ReserveCreditsRequest": {
+            "type": "object",
+            "properties": {
+                "amount": {
+                    "type": "number"
+                }
+            }
+        },
+        "main.RetireCreditsRequest": {
+            "type": "object",
+            "properties": {
+                "beneficiaryName": {
+                    "type": "string"
+                },
+                "quantity": {
+                    "type": "number"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
         "main.Seller": {
             "type": "object",
             "properties": {
@@ -719,6 +3566,35 @@ const docTemplate = `{
                 }
             }
         },
+        "main.TradingVolume": {
+            "type": "object",
+            "properties": {
+                "credits": {
+                    "type": "number"
+                },
+                "trades": {
+                    "type": "integer"
+                },
+                "value": {
+                    "type": "number"
+                },
+                "vwap": {
+                    "description": "Volume-weighted average price per credit, 0 without trades",
+                    "type": "number"
+                }
+            }
+        },
+        "main.TransferCreditsRequest": {
+            "type": "object",
+            "properties": {
+                "quantity": {
+                    "type": "number"
+                },
+                "recipientId": {
+                    "type": "string"
+                }
+            }
+        },
         "main.UpdateListingRequest": {
             "type": "object",
             "properties": {
@@ -731,8 +3607,42 @@ const docTemplate = `{
                 "pricePerCredit": {
                     "type": "number"
                 },
+                "quantity": {
+                    "description": "Optional; changes the credits allocated from the batch",
+                    "type": "number"
+                },
                 "status": {
+                    "description": "Optional; active (publishing a draft) or cancelled",
+                    "type": "string"
+                }
+            }
+        },
+        "main.WalletHolding": {
+            "type": "object",
+            "properties": {
+                "averagePricePerCredit": {
+                    "type": "number"
+                },
+                "biomeType": {
+                    "type": "string"
+                },
+                "landId": {
+                    "type": "string"
+                },
+                "landTitle": {
                     "type": "string"
+                },
+                "purchased": {
+                    "type": "number"
+                },
+                "remaining": {
+                    "type": "number"
+                },
+                "retired": {
+                    "type": "number"
+                },
+                "vintageYear": {
+                    "type": "integer"
                 }
             }
         }
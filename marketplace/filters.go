@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/lib/pq"
+	"gorm.io/gorm"
+)
+
+// creditQuery applies FilterOptions to a query over a table that references
+// carbon_credits, such as credit_listings or credit_auctions. carbon_credits
+// and lands are joined at most once however many filters need them.
+type creditQuery struct {
+	db     *gorm.DB
+	table  string
+	price  string
+	joined bool
+}
+
+func newListingQuery(db *gorm.DB) *creditQuery {
+	return &creditQuery{db: db, table: "credit_listings", price: "credit_listings.price_per_credit"}
+}
+
+func newAuctionQuery(db *gorm.DB) *creditQuery {
+	return &creditQuery{db: db, table: "credit_auctions", price: "credit_auctions.starting_price"}
+}
+
+func (q *creditQuery) joinLands() *creditQuery {
+	if !q.joined {
+		q.db = q.db.Joins("JOIN carbon_credits ON carbon_credits.id = " + q.table + ".carbon_credits_id").
+			Joins("JOIN lands ON lands.id = carbon_credits.land_id")
+		q.joined = true
+	}
+	return q
+}
+
+// whereJoined adds a condition on carbon_credits or lands.
+func (q *creditQuery) whereJoined(query interface{}, args ...interface{}) {
+	q.joinLands()
+	q.db = q.db.Where(query, args...)
+}
+
+// available is how many credits a row offers: a listing's remaining
+// quantity or an auction's lot.
+func (q *creditQuery) available() string {
+	return q.table + ".quantity"
+}
+
+func (q *creditQuery) apply(filter *FilterOptions) *creditQuery {
+	if filter == nil {
+		return q
+	}
+
+	if filter.MinPrice != nil {
+		q.db = q.db.Where(q.price+" >= ?", *filter.MinPrice)
+	}
+	if filter.MaxPrice != nil {
+		q.db = q.db.Where(q.price+" <= ?", *filter.MaxPrice)
+	}
+
+	if filter.MinCredits != nil {
+		q.whereJoined(q.available()+" >= ?", *filter.MinCredits)
+	}
+	if filter.MaxCredits != nil {
+		q.whereJoined(q.available()+" <= ?", *filter.MaxCredits)
+	}
+	if filter.MinVintageYear != nil {
+		q.whereJoined("carbon_credits.vintage_year >= ?", *filter.MinVintageYear)
+	}
+	if filter.MaxVintageYear != nil {
+		q.whereJoined("carbon_credits.vintage_year <= ?", *filter.MaxVintageYear)
+	}
+	if filter.VerificationStandard != nil {
+		q.whereJoined("carbon_credits.verification_standard = ?", *filter.VerificationStandard)
+	}
+	if filter.ExpiresAfter != nil {
+		// Credits without an expiration date never expire
+		q.whereJoined("carbon_credits.expiration_date IS NULL OR carbon_credits.expiration_date >= ?", *filter.ExpiresAfter)
+	}
+
+	if filter.BiomeType != nil {
+		q.whereJoined("lands.biome_type = ?", *filter.BiomeType)
+	}
+	if filter.Location != nil {
+		q.whereJoined("lands.location = ?", *filter.Location)
+	}
+	if filter.LandVerificationStatus != nil {
+		q.whereJoined("lands.verification_status = ?", *filter.LandVerificationStatus)
+	}
+	if filter.MinForestDensity != nil {
+		q.whereJoined("lands.forest_density_percentage >= ?", *filter.MinForestDensity)
+	}
+	if len(filter.TreeSpecies) > 0 {
+		// Any of the requested species, ignoring case
+		species := make([]string, len(filter.TreeSpecies))
+		for i, s := range filter.TreeSpecies {
+			species[i] = strings.ToLower(s)
+		}
+		q.whereJoined("EXISTS (SELECT 1 FROM unnest(lands.tree_species) AS species WHERE LOWER(species) = ANY(?))", pq.Array(species))
+	}
+
+	if filter.BBox != nil {
+		q.joinLands()
+		q.db = withinBoundingBox(q.db, *filter.BBox)
+	}
+	if filter.Near != nil && filter.RadiusKm != nil {
+		q.joinLands()
+		q.db = withinRadius(q.db, *filter.Near, *filter.RadiusKm)
+	}
+	if filter.Query != nil {
+		q.whereJoined("lands.search_vector @@ websearch_to_tsquery('english', ?)", *filter.Query)
+	}
+
+	return q
+}
@@ -0,0 +1,183 @@
+// lands/geo.go copies the helpers above GetLandFeatures; keep the two in
+// sync.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	earthRadiusKm = 6371.0
+	kmPerDegree   = 111.045
+)
+
+func parseGeoPoint(s string) (*GeoPoint, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("expected lat,lon")
+	}
+
+	lat, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || lat < -90 || lat > 90 {
+		return nil, fmt.Errorf("latitude must be between -90 and 90")
+	}
+	lon, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+	if err != nil || lon < -180 || lon > 180 {
+		return nil, fmt.Errorf("longitude must be between -180 and 180")
+	}
+
+	return &GeoPoint{Lat: lat, Lon: lon}, nil
+}
+
+// parseBoundingBox reads minLon,minLat,maxLon,maxLat. A box whose minLon is
+// greater than its maxLon crosses the antimeridian.
+func parseBoundingBox(s string) (*BoundingBox, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return nil, fmt.Errorf("expected minLon,minLat,maxLon,maxLat")
+	}
+
+	var v [4]float64
+	for i, p := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, err
+		}
+		v[i] = f
+	}
+
+	box := &BoundingBox{MinLon: v[0], MinLat: v[1], MaxLon: v[2], MaxLat: v[3]}
+	if box.MinLat < -90 || box.MaxLat > 90 || box.MinLat > box.MaxLat {
+		return nil, fmt.Errorf("latitudes must be between -90 and 90 with minLat <= maxLat")
+	}
+	if box.MinLon < -180 || box.MaxLon > 180 {
+		return nil, fmt.Errorf("longitudes must be between -180 and 180")
+	}
+
+	return box, nil
+}
+
+// distanceKm is the haversine great-circle distance in km from the lands row
+// to p.
+func distanceKm(p GeoPoint) clause.Expr {
+	return clause.Expr{
+		SQL: "? * 2 * ASIN(SQRT(POWER(SIN(RADIANS(lands.latitude - ?) / 2), 2) + " +
+			"COS(RADIANS(?)) * COS(RADIANS(lands.latitude)) * POWER(SIN(RADIANS(lands.longitude - ?) / 2), 2)))",
+		Vars: []interface{}{earthRadiusKm, p.Lat, p.Lat, p.Lon},
+	}
+}
+
+// withinRadius keeps lands within km of p. A bounding box around the circle
+// is checked first so the latitude/longitude index can do most of the work.
+func withinRadius(query *gorm.DB, p GeoPoint, km float64) *gorm.DB {
+	dLat := km / kmPerDegree
+	query = query.Where("lands.latitude BETWEEN ? AND ?", p.Lat-dLat, p.Lat+dLat)
+
+	if cos := math.Cos(p.Lat * math.Pi / 180); cos > 0.01 {
+		if dLon := km / (kmPerDegree * cos); dLon < 180 {
+			minLon, maxLon := p.Lon-dLon, p.Lon+dLon
+			switch {
+			case minLon < -180:
+				query = query.Where("lands.longitude >= ? OR lands.longitude <= ?", minLon+360, maxLon)
+			case maxLon > 180:
+				query = query.Where("lands.longitude >= ? OR lands.longitude <= ?", minLon, maxLon-360)
+			default:
+				query = query.Where("lands.longitude BETWEEN ? AND ?", minLon, maxLon)
+			}
+		}
+	}
+
+	d := distanceKm(p)
+	return query.Where(clause.Expr{SQL: d.SQL + " <= ?", Vars: append(d.Vars, km)})
+}
+
+func withinBoundingBox(query *gorm.DB, box BoundingBox) *gorm.DB {
+	query = query.Where("lands.latitude BETWEEN ? AND ?", box.MinLat, box.MaxLat)
+	if box.MinLon > box.MaxLon {
+		return query.Where("lands.longitude >= ? OR lands.longitude <= ?", box.MinLon, box.MaxLon)
+	}
+	return query.Where("lands.longitude BETWEEN ? AND ?", box.MinLon, box.MaxLon)
+}
+
+// GetLandFeatures exports lands as a GeoJSON FeatureCollection, each with
+// the count and best price of its active listings. With activeOnly, lands
+// without an active listing are left out.
+func (s *MarketSVC) GetLandFeatures(ctx context.Context, activeOnly bool, bbox *BoundingBox) (*LandFeatureCollection, error) {
+	asks := s.db.Model(&CreditListing{}).
+		Select("carbon_credits.land_id, COUNT(*) AS active_listings, MIN(credit_listings.price_per_credit) AS best_price_per_credit").
+		Joins("JOIN carbon_credits ON carbon_credits.id = credit_listings.carbon_credits_id").
+		Where("credit_listings.status = ?", "active").
+		Group("carbon_credits.land_id")
+
+	join := "LEFT JOIN"
+	if activeOnly {
+		join = "JOIN"
+	}
+
+	query := s.db.WithContext(ctx).
+		Table("lands").
+		Select(`lands.id, lands.title, lands.location, lands.latitude, lands.longitude,
+			lands.biome_type, lands.size_square_meters, lands.verification_status,
+			lands.forest_density_percentage,
+			COALESCE(asks.active_listings, 0) AS active_listings,
+			asks.best_price_per_credit`).
+		Joins(join+" (?) AS asks ON asks.land_id = lands.id", asks).
+		Order("lands.id")
+
+	if bbox != nil {
+		query = withinBoundingBox(query, *bbox)
+	}
+
+	var rows []struct {
+		ID                      uuid.UUID
+		Title                   string
+		Location                string
+		Latitude                float64
+		Longitude               float64
+		BiomeType               string
+		SizeSquareMeters        float64
+		VerificationStatus      string
+		ForestDensityPercentage *float64
+		ActiveListings          int64
+		BestPricePerCredit      *float64
+	}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	collection := &LandFeatureCollection{
+		Type:     "FeatureCollection",
+		Features: make([]LandFeature, 0, len(rows)),
+	}
+	for _, row := range rows {
+		collection.Features = append(collection.Features, LandFeature{
+			Type: "Feature",
+			ID:   row.ID,
+			Geometry: PointGeometry{
+				Type:        "Point",
+				Coordinates: [2]float64{row.Longitude, row.Latitude},
+			},
+			Properties: LandFeatureProperties{
+				Title:                   row.Title,
+				Location:                row.Location,
+				BiomeType:               row.BiomeType,
+				SizeSquareMeters:        row.SizeSquareMeters,
+				VerificationStatus:      row.VerificationStatus,
+				ForestDensityPercentage: row.ForestDensityPercentage,
+				ActiveListings:          row.ActiveListings,
+				BestPricePerCredit:      row.BestPricePerCredit,
+			},
+		})
+	}
+
+	return collection, nil
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"context"
 	_ "marketplace-service/docs"
@@ -69,12 +70,80 @@ func main() {
 	log.Println("Database initialized successfully")
 	defer sqlDB.Close()
 
-	handler := NewHandler(NewMarketSVC(db))
+	settleInterval, err := time.ParseDuration(getEnv("AUCTION_SETTLE_INTERVAL", "30s"))
+	if err != nil {
+		log.Fatalf("invalid AUCTION_SETTLE_INTERVAL: %v", err)
+	}
+
+	antiSnipeWindow, err := time.ParseDuration(getEnv("AUCTION_ANTI_SNIPE_WINDOW", "2m"))
+	if err != nil {
+		log.Fatalf("invalid AUCTION_ANTI_SNIPE_WINDOW: %v", err)
+	}
+
+	reservationTTL, err := time.ParseDuration(getEnv("RESERVATION_TTL", "15m"))
+	if err != nil {
+		log.Fatalf("invalid RESERVATION_TTL: %v", err)
+	}
+
+	refundGracePeriod, err := time.ParseDuration(getEnv("REFUND_GRACE_PERIOD", "24h"))
+	if err != nil {
+		log.Fatalf("invalid REFUND_GRACE_PERIOD: %v", err)
+	}
+
+	summaryCacheTTL, err := time.ParseDuration(getEnv("MARKET_SUMMARY_CACHE_TTL", "1m"))
+	if err != nil {
+		log.Fatalf("invalid MARKET_SUMMARY_CACHE_TTL: %v", err)
+	}
+
+	// There is no default provider: a deployment that forgets to configure
+	// one must not start taking orders against the fake
+	webhookSecret := os.Getenv("PAYMENT_WEBHOOK_SECRET")
+	if webhookSecret == "" {
+		log.Fatal("PAYMENT_WEBHOOK_SECRET must be set")
+	}
+
+	var payments PaymentProvider
+	switch provider := os.Getenv("PAYMENT_PROVIDER"); provider {
+	case "fake":
+		log.Println("Using the fake payment provider; no real payments are taken")
+		payments = NewFakePaymentProvider(webhookSecret)
+	case "":
+		log.Fatal("PAYMENT_PROVIDER must be set")
+	default:
+		log.Fatalf("unknown PAYMENT_PROVIDER %q", provider)
+	}
+
+	svc := NewMarketSVC(db, MarketConfig{
+		AntiSnipeWindow:   antiSnipeWindow,
+		ReservationTTL:    reservationTTL,
+		RefundGracePeriod: refundGracePeriod,
+		SummaryCacheTTL:   summaryCacheTTL,
+	}, payments)
+	go NewAuctionSettler(db, payments, settleInterval).Run(ctx)
+	go runReservationSweeper(ctx, svc, time.Minute)
+	go runListingSweeper(ctx, svc, time.Minute)
+	go runRefundSweeper(ctx, svc, time.Minute)
+
+	handler := NewHandler(svc)
 
 	http.HandleFunc("GET /api/market/swagger/", httpSwagger.WrapHandler)
 	http.HandleFunc("GET /api/market/{$}", handler.handleHealthCheck)
 	http.HandleFunc("GET /api/market/active", handler.handleActiveListings)
 	http.HandleFunc("GET /api/market/active/{id}", handler.handleActiveListingsByID)
+	http.HandleFunc("GET /api/market/lands/geojson", handler.handleLandFeatures)
+	http.HandleFunc("POST /api/market/active/{id}/orders", handler.handlePlaceOrder)
+	http.HandleFunc("POST /api/market/purchases/{id}/refund", handler.handleRefundPurchase)
+	http.HandleFunc("GET /api/market/private/wallets", handler.handleWalletHoldings)
+	http.HandleFunc("POST /api/market/private/wallets/{id}/transfers", handler.handleTransferCredits)
+	http.HandleFunc("POST /api/market/private/wallets/{id}/retire", handler.handleRetireCredits)
+	http.HandleFunc("POST /api/market/private/wallets/{id}/listings", handler.handleCreateResaleListing)
+	http.HandleFunc("DELETE /api/market/resale/{id}", handler.handleCancelResaleListing)
+	http.HandleFunc("GET /api/market/private/retirements/{id}/certificate", handler.handleRetirementCertificate)
+	http.HandleFunc("GET /api/market/certificates/{certificateId}", handler.handleVerifyCertificate)
+	http.HandleFunc("POST /api/market/active/{id}/reservations", handler.handleReserveCredits)
+	http.HandleFunc("POST /api/market/reservations/{id}/confirm", handler.handleConfirmReservation)
+	http.HandleFunc("DELETE /api/market/reservations/{id}", handler.handleReleaseReservation)
+	http.HandleFunc("POST /api/market/payments/webhook", handler.handlePaymentWebhook)
 
 	http.HandleFunc("GET /api/market/private", handler.handlePrivateListings)
 	http.HandleFunc("GET /api/market/private/{id}", handler.handlePrivateListingsByID)
@@ -82,6 +151,23 @@ func main() {
 	http.HandleFunc("POST /api/market/private", handler.handleCreateListing)
 	http.HandleFunc("PUT /api/market/private/{id}", handler.handleUpdateListing)
 	http.HandleFunc("DELETE /api/market/private/{id}", handler.handleDeleteListing)
+	http.HandleFunc("POST /api/market/private/{id}/publish", handler.handlePublishListing)
+	http.HandleFunc("POST /api/market/private/{id}/cancel", handler.handleCancelListing)
+	http.HandleFunc("GET /api/market/private/credits/{id}/availability", handler.handleCreditAvailability)
+	http.HandleFunc("GET /api/market/private/lands/{id}/verification", handler.handleLandVerification)
+
+	http.HandleFunc("GET /api/market/private/buy-orders", handler.handleBuyOrders)
+	http.HandleFunc("POST /api/market/private/buy-orders", handler.handleCreateBuyOrder)
+	http.HandleFunc("DELETE /api/market/private/buy-orders/{id}", handler.handleCancelBuyOrder)
+
+	http.HandleFunc("GET /api/market/auctions", handler.handleActiveAuctions)
+	http.HandleFunc("GET /api/market/auctions/{id}", handler.handleAuctionByID)
+	http.HandleFunc("POST /api/market/auctions", handler.handleCreateAuction)
+	http.HandleFunc("POST /api/market/auctions/{id}/bids", handler.handlePlaceBid)
+	http.HandleFunc("PUT /api/market/auctions/{id}/proxy", handler.handleSetProxyBid)
+
+	http.HandleFunc("GET /api/market/stats/candles", handler.handlePriceCandles)
+	http.HandleFunc("GET /api/market/stats/summary", handler.handleMarketSummary)
 
 	port := os.Getenv("PORT")
 	if port == "" {
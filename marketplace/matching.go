@@ -0,0 +1,335 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func (s *MarketSVC) CreateBuyOrder(ctx context.Context, userID uuid.UUID, req CreateBuyOrderRequest) (*BuyOrder, error) {
+	if req.Quantity <= 0 || req.MaxPricePerCredit <= 0 {
+		return nil, ErrInvalidBuyOrder
+	}
+
+	now := time.Now()
+	order := &BuyOrder{
+		BuyerID:              userID,
+		Quantity:             req.Quantity,
+		MaxPricePerCredit:    req.MaxPricePerCredit,
+		BiomeType:            req.BiomeType,
+		Location:             req.Location,
+		MinVintageYear:       req.MinVintageYear,
+		VerificationStandard: req.VerificationStandard,
+		Status:               "open",
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+
+	if err := s.db.WithContext(ctx).Create(order).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.matchBuyOrder(ctx, order.ID); err != nil {
+		log.Printf("matching buy order %s failed: %v", order.ID, err)
+	}
+
+	if err := s.db.WithContext(ctx).First(order, "id = ?", order.ID).Error; err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+func (s *MarketSVC) GetBuyOrders(ctx context.Context, userID uuid.UUID, page, limit int) ([]BuyOrder, error) {
+	var orders []BuyOrder
+
+	if err := s.db.WithContext(ctx).
+		Where("buyer_id = ?", userID).
+		Order("created_at DESC").
+		Offset((page - 1) * limit).
+		Limit(limit).
+		Find(&orders).Error; err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (s *MarketSVC) CancelBuyOrder(ctx context.Context, userID, orderID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Model(&BuyOrder{}).
+		Where("id = ? AND buyer_id = ? AND status = ?", orderID, userID, "open").
+		Updates(map[string]interface{}{
+			"status":     "cancelled",
+			"updated_at": time.Now(),
+		})
+
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return ErrBuyOrderNotFound
+	}
+
+	return nil
+}
+
+// matchListing fills open buy orders against an active listing, best price
+// first and oldest first among equal prices. Orders locked by a concurrent
+// match are skipped rather than waited on, which also keeps this from
+// deadlocking with matchBuyOrder, which locks in the opposite order.
+func (s *MarketSVC) matchListing(ctx context.Context, listingID uuid.UUID) error {
+	var fills []*CreditReservation
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var listing CreditListing
+		if err := tx.Where("id = ? AND status = ?", listingID, "active").First(&listing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		var credit CarbonCredit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Land").
+			Where("id = ?", listing.CarbonCreditsID).
+			First(&credit).Error; err != nil {
+			return err
+		}
+
+		// Left for the listing sweeper to pull
+		if err := checkListable(tx, credit.ID); isUnlistable(err) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		var orders []BuyOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND max_price_per_credit >= ?", "open", listing.PricePerCredit).
+			Where("biome_type IS NULL OR biome_type = ?", credit.Land.BiomeType).
+			Where("location IS NULL OR location = ?", credit.Land.Location).
+			Where("min_vintage_year IS NULL OR min_vintage_year <= ?", credit.VintageYear).
+			Where("verification_standard IS NULL OR verification_standard = ?", credit.VerificationStandard).
+			Order("max_price_per_credit DESC, created_at ASC").
+			Find(&orders).Error; err != nil {
+			return err
+		}
+
+		for i := range orders {
+			if listing.Status != "active" {
+				break
+			}
+			fill, err := s.fillBuyOrder(tx, &orders[i], &listing, &credit)
+			if err != nil {
+				return err
+			}
+			if fill != nil {
+				fills = append(fills, fill)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.payFills(ctx, fills)
+	return nil
+}
+
+// matchBuyOrder fills a buy order against the cheapest matching active
+// listings, oldest first among equal prices.
+func (s *MarketSVC) matchBuyOrder(ctx context.Context, orderID uuid.UUID) error {
+	var fills []*CreditReservation
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var order BuyOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status = ?", orderID, "open").
+			First(&order).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil
+			}
+			return err
+		}
+
+		query := tx.Model(&CreditListing{}).
+			Joins("JOIN carbon_credits ON carbon_credits.id = credit_listings.carbon_credits_id").
+			Joins("JOIN lands ON lands.id = carbon_credits.land_id").
+			Where("credit_listings.status = ? AND credit_listings.price_per_credit <= ?", "active", order.MaxPricePerCredit)
+
+		if order.BiomeType != nil {
+			query = query.Where("lands.biome_type = ?", *order.BiomeType)
+		}
+		if order.Location != nil {
+			query = query.Where("lands.location = ?", *order.Location)
+		}
+		if order.MinVintageYear != nil {
+			query = query.Where("carbon_credits.vintage_year >= ?", *order.MinVintageYear)
+		}
+		if order.VerificationStandard != nil {
+			query = query.Where("carbon_credits.verification_standard = ?", *order.VerificationStandard)
+		}
+
+		var listings []CreditListing
+		if err := query.Order("credit_listings.price_per_credit ASC, credit_listings.created_at ASC").
+			Find(&listings).Error; err != nil {
+			return err
+		}
+
+		for i := range listings {
+			if order.Status != "open" {
+				break
+			}
+
+			var credit CarbonCredit
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", listings[i].CarbonCreditsID).
+				First(&credit).Error; err != nil {
+				return err
+			}
+
+			if err := checkListable(tx, credit.ID); isUnlistable(err) {
+				continue
+			} else if err != nil {
+				return err
+			}
+
+			fill, err := s.fillBuyOrder(tx, &order, &listings[i], &credit)
+			if err != nil {
+				return err
+			}
+			if fill != nil {
+				fills = append(fills, fill)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.payFills(ctx, fills)
+	return nil
+}
+
+// fillBuyOrder holds as much of the order's outstanding quantity on the
+// listing as its purchase limits and the batch allow, counts it as filled and
+// marks the order filled once nothing is outstanding. Fills below the
+// listing's minimum purchase, listings that are no longer active and the
+// buyer's own listings are skipped. The hold is paid for by payFills once the
+// match has committed.
+func (s *MarketSVC) fillBuyOrder(tx *gorm.DB, order *BuyOrder, listing *CreditListing, credit *CarbonCredit) (*CreditReservation, error) {
+	available, err := purchasableCredits(tx, listing)
+	if err != nil {
+		return nil, err
+	}
+	if listing.Status != "active" {
+		return nil, nil
+	}
+
+	own, err := ownsListing(tx, order.BuyerID, listing)
+	if err != nil || own {
+		return nil, err
+	}
+
+	amount := math.Min(order.Quantity-order.QuantityFilled, available)
+	if listing.MaximumPurchase != nil {
+		amount = math.Min(amount, *listing.MaximumPurchase)
+	}
+	if amount <= 0 || amount < listing.MinimumPurchase {
+		return nil, nil
+	}
+
+	now := time.Now()
+	fill := &CreditReservation{
+		ListingID:       listing.ID,
+		CarbonCreditsID: credit.ID,
+		BuyerID:         order.BuyerID,
+		BuyOrderID:      &order.ID,
+		Amount:          amount,
+		PricePerCredit:  listing.PricePerCredit,
+		Status:          "held",
+		ExpiresAt:       now.Add(s.cfg.ReservationTTL),
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := tx.Create(fill).Error; err != nil {
+		return nil, err
+	}
+
+	order.QuantityFilled += amount
+	if order.QuantityFilled >= order.Quantity {
+		order.Status = "filled"
+	}
+
+	return fill, tx.Model(order).Updates(map[string]interface{}{
+		"quantity_filled": order.QuantityFilled,
+		"status":          order.Status,
+		"updated_at":      now,
+	}).Error
+}
+
+// payFills charges buy order buyers for the holds a match took, outside the
+// match's locks, and turns them into purchases. A fill that cannot be paid
+// gives its quantity back to the order; a declined payment also cancels the
+// order.
+func (s *MarketSVC) payFills(ctx context.Context, fills []*CreditReservation) {
+	for _, fill := range fills {
+		err := s.attachPaymentIntent(ctx, fill)
+		if err == nil {
+			_, err = s.payHold(ctx, fill)
+		}
+		if err == nil {
+			continue
+		}
+
+		log.Printf("paying buy order %s fill %s failed: %v", *fill.BuyOrderID, fill.ID, err)
+		s.releaseHold(ctx, fill)
+
+		status := gorm.Expr("CASE WHEN status = 'filled' THEN 'open' ELSE status END")
+		if errors.Is(err, ErrPaymentFailed) {
+			status = gorm.Expr("'cancelled'")
+		}
+		if err := s.db.WithContext(ctx).
+			Model(&BuyOrder{}).
+			Where("id = ?", *fill.BuyOrderID).
+			Updates(map[string]interface{}{
+				"quantity_filled": gorm.Expr("quantity_filled - ?", fill.Amount),
+				"status":          status,
+				"updated_at":      time.Now(),
+			}).Error; err != nil {
+			log.Printf("returning fill %s to buy order %s failed: %v", fill.ID, *fill.BuyOrderID, err)
+		}
+	}
+}
+
+// ownsListing reports whether userID is the seller of a primary listing's
+// land or the reseller behind a resale listing.
+func ownsListing(tx *gorm.DB, userID uuid.UUID, listing *CreditListing) (bool, error) {
+	var count int64
+	if listing.WalletID != nil {
+		err := tx.Model(&CreditWallet{}).
+			Where("id = ? AND owner_id = ?", *listing.WalletID, userID).
+			Count(&count).Error
+		return count > 0, err
+	}
+
+	err := tx.Model(&CarbonCredit{}).
+		Joins("JOIN lands ON carbon_credits.land_id = lands.id").
+		Joins("JOIN sellers ON lands.owner_id = sellers.id").
+		Where("carbon_credits.id = ? AND sellers.user_id = ?", listing.CarbonCreditsID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
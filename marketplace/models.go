@@ -74,9 +74,157 @@ type CreditListing struct {
 	MinimumPurchase float64   `gorm:"type:numeric(10,2);not null"`
 	MaximumPurchase *float64  `gorm:"type:numeric(10,2)"`
 	Status          string    `gorm:"type:listing_status;default:'draft'"`
-	CreatedAt       time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
-	UpdatedAt       time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	// How many credits are still offered: allocated from the batch, or on
+	// resale listings reserved from the buyer wallet entry set in WalletID
+	Quantity  float64    `gorm:"type:numeric(12,2);not null"`
+	WalletID  *uuid.UUID `gorm:"type:uuid"`
+	CreatedAt time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
 
 	// Relationships
 	CarbonCredit CarbonCredit `gorm:"foreignKey:CarbonCreditsID"` // Many-to-one with CarbonCredit
 }
+
+type CreditReservation struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ListingID       uuid.UUID `gorm:"type:uuid;not null"`
+	CarbonCreditsID uuid.UUID `gorm:"type:uuid;not null"`
+	BuyerID         uuid.UUID `gorm:"type:uuid;not null"`
+	// Set on holds a buy order fill takes while its buyer is charged
+	BuyOrderID      *uuid.UUID `gorm:"type:uuid"`
+	Amount          float64    `gorm:"type:numeric(10,2);not null"`
+	PricePerCredit  float64    `gorm:"type:numeric(10,2);not null"`
+	Status          string     `gorm:"type:reservation_status;default:'held'"`
+	ExpiresAt       time.Time  `gorm:"type:timestamptz;not null"`
+	PaymentIntentID *string    `gorm:"type:varchar(255)"`
+	PurchaseID      *uuid.UUID `gorm:"type:uuid"`
+	// Set when the hold was paid but never became a purchase and the
+	// payment was refunded
+	RefundedAt *time.Time `gorm:"type:timestamptz"`
+	CreatedAt  time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	UpdatedAt  time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+}
+
+type Purchase struct {
+	ID              uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	BuyerID         uuid.UUID  `gorm:"type:uuid;not null"`
+	CarbonCreditsID uuid.UUID  `gorm:"type:uuid;not null"`
+	ListingID       *uuid.UUID `gorm:"type:uuid"`
+	AuctionID       *uuid.UUID `gorm:"type:uuid"`
+	BuyOrderID      *uuid.UUID `gorm:"type:uuid"`
+	Amount          float64    `gorm:"type:numeric(10,2);not null"`
+	PricePerCredit  float64    `gorm:"type:numeric(10,2);not null"`
+	TotalPrice      float64    `gorm:"type:numeric(10,2);not null"`
+	PurchaseDate    time.Time  `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	TransactionHash *string    `gorm:"type:varchar(255)"`
+	RefundedAt      *time.Time `gorm:"type:timestamptz"`
+	// Set once the payment is back with the buyer; a refunded purchase
+	// without it is waiting for a gateway refund to be retried
+	PaymentRefundedAt *time.Time `gorm:"type:timestamptz"`
+
+	// Relationships
+	CarbonCredit CarbonCredit `gorm:"foreignKey:CarbonCreditsID"`
+}
+
+type CreditWallet struct {
+	ID               uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	OwnerID          uuid.UUID `gorm:"type:uuid;not null"`
+	PurchaseID       uuid.UUID `gorm:"type:uuid;not null"`
+	CreditsRemaining float64   `gorm:"type:numeric(10,2);not null"`
+	CreatedAt        time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	UpdatedAt        time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+
+	// Relationships
+	Purchase Purchase `gorm:"foreignKey:PurchaseID"`
+}
+
+// CreditTransfer records credits moved from one owner's wallet entry to a new
+// entry of another owner. Both entries keep the original purchase_id.
+type CreditTransfer struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	PurchaseID    uuid.UUID `gorm:"type:uuid;not null"`
+	FromWalletID  uuid.UUID `gorm:"type:uuid;not null"`
+	ToWalletID    uuid.UUID `gorm:"type:uuid;not null"`
+	FromOwnerID   uuid.UUID `gorm:"type:uuid;not null"`
+	ToOwnerID     uuid.UUID `gorm:"type:uuid;not null"`
+	Quantity      float64   `gorm:"type:numeric(10,2);not null"`
+	TransferredAt time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+}
+
+type CreditAuction struct {
+	ID                       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CarbonCreditsID          uuid.UUID `gorm:"type:uuid;not null"`
+	AuctionType              string    `gorm:"type:auction_type;default:'english'"`
+	StartingPrice            float64   `gorm:"type:numeric(10,2);not null"`
+	ReservePrice             *float64  `gorm:"type:numeric(10,2)"`
+	MinIncrement             float64   `gorm:"type:numeric(10,2);not null"`
+	PriceDropIntervalSeconds *int      `gorm:"type:int"`
+	// Credits set aside from the batch for the winner
+	Quantity  float64   `gorm:"type:numeric(12,2);not null"`
+	StartTime time.Time `gorm:"type:timestamptz;not null"`
+	EndTime   time.Time `gorm:"type:timestamptz;not null"`
+	// pending, active, completed, cancelled, or failed once settlement gave up
+	Status string `gorm:"type:auction_status;default:'pending'"`
+	// Failed settlement attempts and the last error, for operators
+	SettlementAttempts int       `gorm:"type:int;not null;default:0"`
+	SettlementError    *string   `gorm:"type:text"`
+	CreatedAt          time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	UpdatedAt          time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+
+	// Current asking price of a Dutch auction, computed on read
+	CurrentPrice *float64 `gorm:"-"`
+
+	// Relationships
+	CarbonCredit CarbonCredit `gorm:"foreignKey:CarbonCreditsID"`
+	Bids         []AuctionBid `gorm:"foreignKey:AuctionID"`
+}
+
+type AuctionBid struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AuctionID uuid.UUID `gorm:"type:uuid;not null"`
+	BidderID  uuid.UUID `gorm:"type:uuid;not null"`
+	BidAmount float64   `gorm:"type:numeric(10,2);not null"`
+	BidTime   time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	Automatic bool      `gorm:"not null;default:false"`
+}
+
+type AuctionProxyBid struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	AuctionID uuid.UUID `gorm:"type:uuid;not null"`
+	BidderID  uuid.UUID `gorm:"type:uuid;not null"`
+	MaxAmount float64   `gorm:"type:numeric(10,2);not null"`
+	CreatedAt time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	UpdatedAt time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+}
+
+type BuyOrder struct {
+	ID                   uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	BuyerID              uuid.UUID `gorm:"type:uuid;not null"`
+	Quantity             float64   `gorm:"type:numeric(10,2);not null"`
+	QuantityFilled       float64   `gorm:"type:numeric(10,2);default:0"`
+	MaxPricePerCredit    float64   `gorm:"type:numeric(10,2);not null"`
+	BiomeType            *string   `gorm:"type:varchar(50)"`
+	Location             *string   `gorm:"type:varchar(100)"`
+	MinVintageYear       *int      `gorm:"type:int"`
+	VerificationStandard *string   `gorm:"type:varchar(50)"`
+	Status               string    `gorm:"type:buy_order_status;default:'open'"`
+	CreatedAt            time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+	UpdatedAt            time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+}
+
+type CreditRetirement struct {
+	ID              uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	CertificateID   string    `gorm:"type:varchar(32);not null;unique"`
+	WalletID        uuid.UUID `gorm:"type:uuid;not null"`
+	OwnerID         uuid.UUID `gorm:"type:uuid;not null"`
+	PurchaseID      uuid.UUID `gorm:"type:uuid;not null"`
+	CarbonCreditsID uuid.UUID `gorm:"type:uuid;not null"`
+	VintageYear     *int      `gorm:"type:int"`
+	Quantity        float64   `gorm:"type:numeric(10,2);not null"`
+	BeneficiaryName string    `gorm:"type:varchar(255);not null"`
+	Reason          *string   `gorm:"type:text"`
+	RetiredAt       time.Time `gorm:"type:timestamptz;default:CURRENT_TIMESTAMP"`
+
+	// Relationships
+	CarbonCredit CarbonCredit `gorm:"foreignKey:CarbonCreditsID"`
+}
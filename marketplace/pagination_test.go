@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	price := "12.50"
+	created := "2026-10-18 07:59:17.123456+00"
+
+	tests := []struct {
+		name string
+		c    cursor
+	}{
+		{"price", cursor{Sort: "price", Value: &price, ID: uuid.New()}},
+		{"descending time", cursor{Sort: "-createdAt", Value: &created, ID: uuid.New()}},
+		{"null sort value", cursor{Sort: "vintage", ID: uuid.New()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeCursor(encodeCursor(tt.c), tt.c.Sort)
+			if err != nil {
+				t.Fatalf("decodeCursor: %v", err)
+			}
+			if got.Sort != tt.c.Sort || got.ID != tt.c.ID {
+				t.Fatalf("decoded %+v, want %+v", got, tt.c)
+			}
+			if (got.Value == nil) != (tt.c.Value == nil) || (got.Value != nil && *got.Value != *tt.c.Value) {
+				t.Fatalf("decoded value %v, want %v", got.Value, tt.c.Value)
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	value := "10"
+	valid := encodeCursor(cursor{Sort: "price", Value: &value, ID: uuid.New()})
+
+	if c, err := decodeCursor("", "price"); c != nil || err != nil {
+		t.Fatalf("decodeCursor(\"\") = %v, %v, want the first page", c, err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		sort  string
+	}{
+		{"other sort", valid, "-price"},
+		{"not base64", "not a cursor!", "price"},
+		{"not json", base64.RawURLEncoding.EncodeToString([]byte("price:10")), "price"},
+		{"missing id", base64.RawURLEncoding.EncodeToString([]byte(`{"s":"price","v":"10"}`)), "price"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeCursor(tt.token, tt.sort); !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("decodeCursor error = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
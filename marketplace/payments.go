@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+)
+
+const paymentCurrency = "eur"
+
+type PaymentIntent struct {
+	ID       string  `json:"id"`
+	Amount   float64 `json:"amount"`
+	Currency string  `json:"currency"`
+	// requires_confirmation, succeeded, failed or refunded
+	Status    string `json:"status"`
+	Reference string `json:"reference"`
+}
+
+type PaymentEvent struct {
+	// payment_succeeded, payment_failed or refund_succeeded
+	Type     string `json:"type"`
+	IntentID string `json:"intentId"`
+}
+
+// PaymentProvider is the gateway that moves the buyer's money. The marketplace
+// creates an intent when checkout starts, confirms it before handing over
+// credits, refunds it when a purchase is reversed, and learns about
+// asynchronous outcomes through webhooks.
+type PaymentProvider interface {
+	CreateIntent(ctx context.Context, amount float64, currency, reference string) (*PaymentIntent, error)
+	ConfirmIntent(ctx context.Context, intentID string) (*PaymentIntent, error)
+	Refund(ctx context.Context, intentID string, amount float64) error
+	HandleWebhook(ctx context.Context, payload []byte, signature string) (*PaymentEvent, error)
+}
+
+// chargePayment takes amount from the buyer in one step, for purchases that
+// are paid when they are made rather than through a checkout hold.
+func chargePayment(ctx context.Context, payments PaymentProvider, amount float64, reference string) (*PaymentIntent, error) {
+	intent, err := payments.CreateIntent(ctx, amount, paymentCurrency, reference)
+	if err != nil {
+		return nil, err
+	}
+	if intent, err = payments.ConfirmIntent(ctx, intent.ID); err != nil {
+		return nil, err
+	}
+	if intent.Status != "succeeded" {
+		return nil, ErrPaymentFailed
+	}
+	return intent, nil
+}
+
+// refundCharges gives back payments taken inside a transaction that was
+// rolled back. Failures are logged, as the caller is already failing.
+func refundCharges(ctx context.Context, payments PaymentProvider, charged []*PaymentIntent) {
+	for _, intent := range charged {
+		if err := payments.Refund(ctx, intent.ID, intent.Amount); err != nil {
+			log.Printf("refunding payment %s failed: %v", intent.ID, err)
+		}
+	}
+}
+
+// FakePaymentProvider is an in-process PaymentProvider for local development
+// and tests, enabled only with PAYMENT_PROVIDER=fake. Intent IDs are
+// sequential, every confirmation succeeds, and webhooks are JSON
+// PaymentEvents signed with a hex HMAC-SHA256 of the body. Intents live in
+// memory, so it only works with a single instance and forgets them on
+// restart.
+type FakePaymentProvider struct {
+	mu      sync.Mutex
+	secret  []byte
+	next    int
+	intents map[string]*PaymentIntent
+}
+
+func NewFakePaymentProvider(webhookSecret string) *FakePaymentProvider {
+	return &FakePaymentProvider{
+		secret:  []byte(webhookSecret),
+		intents: make(map[string]*PaymentIntent),
+	}
+}
+
+func (p *FakePaymentProvider) CreateIntent(ctx context.Context, amount float64, currency, reference string) (*PaymentIntent, error) {
+	if amount <= 0 {
+		return nil, ErrPaymentFailed
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.next++
+	intent := &PaymentIntent{
+		ID:        fmt.Sprintf("pi_fake_%06d", p.next),
+		Amount:    amount,
+		Currency:  currency,
+		Status:    "requires_confirmation",
+		Reference: reference,
+	}
+	p.intents[intent.ID] = intent
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *FakePaymentProvider) ConfirmIntent(ctx context.Context, intentID string) (*PaymentIntent, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok {
+		return nil, ErrPaymentFailed
+	}
+	if intent.Status == "requires_confirmation" {
+		intent.Status = "succeeded"
+	}
+
+	copied := *intent
+	return &copied, nil
+}
+
+func (p *FakePaymentProvider) Refund(ctx context.Context, intentID string, amount float64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[intentID]
+	if !ok || intent.Status != "succeeded" || amount <= 0 || amount > intent.Amount {
+		return fmt.Errorf("cannot refund intent %s", intentID)
+	}
+
+	intent.Amount -= amount
+	if intent.Amount <= 0 {
+		intent.Status = "refunded"
+	}
+
+	return nil
+}
+
+func (p *FakePaymentProvider) HandleWebhook(ctx context.Context, payload []byte, signature string) (*PaymentEvent, error) {
+	mac := hmac.New(sha256.New, p.secret)
+	mac.Write(payload)
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+		return nil, ErrInvalidWebhookSignature
+	}
+
+	var event PaymentEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	intent, ok := p.intents[event.IntentID]
+	if !ok {
+		return nil, fmt.Errorf("unknown intent %s", event.IntentID)
+	}
+
+	switch event.Type {
+	case "payment_succeeded":
+		intent.Status = "succeeded"
+	case "payment_failed":
+		intent.Status = "failed"
+	case "refund_succeeded":
+		intent.Status = "refunded"
+	default:
+		return nil, fmt.Errorf("unknown webhook event type %q", event.Type)
+	}
+
+	return &event, nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func signWebhook(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestFakePaymentProviderChargeAndRefund(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakePaymentProvider("secret")
+
+	intent, err := p.CreateIntent(ctx, 100, paymentCurrency, "ref")
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	if intent.Status != "requires_confirmation" {
+		t.Fatalf("new intent status = %q, want requires_confirmation", intent.Status)
+	}
+
+	if err := p.Refund(ctx, intent.ID, 100); err == nil {
+		t.Fatal("refunding an unconfirmed intent succeeded")
+	}
+
+	intent, err = p.ConfirmIntent(ctx, intent.ID)
+	if err != nil {
+		t.Fatalf("ConfirmIntent: %v", err)
+	}
+	if intent.Status != "succeeded" {
+		t.Fatalf("confirmed intent status = %q, want succeeded", intent.Status)
+	}
+
+	if err := p.Refund(ctx, intent.ID, 150); err == nil {
+		t.Fatal("refunding more than was charged succeeded")
+	}
+	if err := p.Refund(ctx, intent.ID, 40); err != nil {
+		t.Fatalf("partial Refund: %v", err)
+	}
+	if err := p.Refund(ctx, intent.ID, 60); err != nil {
+		t.Fatalf("remaining Refund: %v", err)
+	}
+	if got := p.intents[intent.ID].Status; got != "refunded" {
+		t.Fatalf("refunded intent status = %q, want refunded", got)
+	}
+	if err := p.Refund(ctx, intent.ID, 1); err == nil {
+		t.Fatal("refunding a fully refunded intent succeeded")
+	}
+}
+
+func TestFakePaymentProviderRejectsInvalidAmount(t *testing.T) {
+	p := NewFakePaymentProvider("secret")
+
+	if _, err := p.CreateIntent(context.Background(), 0, paymentCurrency, "ref"); !errors.Is(err, ErrPaymentFailed) {
+		t.Fatalf("CreateIntent(0) error = %v, want ErrPaymentFailed", err)
+	}
+}
+
+func TestFakePaymentProviderWebhook(t *testing.T) {
+	ctx := context.Background()
+	p := NewFakePaymentProvider("secret")
+
+	intent, err := p.CreateIntent(ctx, 10, paymentCurrency, "ref")
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+	payload := []byte(`{"type":"payment_succeeded","intentId":"` + intent.ID + `"}`)
+
+	tests := []struct {
+		name      string
+		signature string
+	}{
+		{"missing signature", ""},
+		{"wrong secret", signWebhook("other", payload)},
+		{"tampered payload", signWebhook("secret", []byte(`{"type":"payment_failed","intentId":"`+intent.ID+`"}`))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := p.HandleWebhook(ctx, payload, tt.signature); !errors.Is(err, ErrInvalidWebhookSignature) {
+				t.Fatalf("HandleWebhook error = %v, want ErrInvalidWebhookSignature", err)
+			}
+			if got := p.intents[intent.ID].Status; got != "requires_confirmation" {
+				t.Fatalf("intent status = %q after rejected webhook, want requires_confirmation", got)
+			}
+		})
+	}
+
+	event, err := p.HandleWebhook(ctx, payload, signWebhook("secret", payload))
+	if err != nil {
+		t.Fatalf("HandleWebhook with valid signature: %v", err)
+	}
+	if event.Type != "payment_succeeded" || event.IntentID != intent.ID {
+		t.Fatalf("event = %+v, want payment_succeeded for %s", event, intent.ID)
+	}
+	if got := p.intents[intent.ID].Status; got != "succeeded" {
+		t.Fatalf("intent status = %q, want succeeded", got)
+	}
+}
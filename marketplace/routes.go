@@ -1,12 +1,15 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -21,7 +24,7 @@ func getPaginationParams(r *http.Request) (int, int) {
 	limitStr := r.URL.Query().Get("limit")
 
 	page := 1
-	limit := 10
+	limit := defaultPageLimit
 
 	if pageStr != "" {
 		page, _ = strconv.Atoi(pageStr)
@@ -30,9 +33,43 @@ func getPaginationParams(r *http.Request) (int, int) {
 		limit, _ = strconv.Atoi(limitStr)
 	}
 
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = defaultPageLimit
+	}
+	if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
 	return page, limit
 }
 
+func getPageRequest(r *http.Request) (PageRequest, error) {
+	qs := r.URL.Query()
+
+	req := PageRequest{Cursor: qs.Get("cursor"), Limit: defaultPageLimit}
+
+	if limitStr := qs.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return req, fmt.Errorf("invalid limit: must be a positive integer")
+		}
+		req.Limit = min(limit, maxPageLimit)
+	}
+
+	if totalStr := qs.Get("total"); totalStr != "" {
+		withTotal, err := strconv.ParseBool(totalStr)
+		if err != nil {
+			return req, fmt.Errorf("invalid total: %v", err)
+		}
+		req.WithTotal = withTotal
+	}
+
+	return req, nil
+}
+
 func getFilters(r *http.Request) (*FilterOptions, error) {
 	qs := r.URL.Query()
 
@@ -54,6 +91,22 @@ func getFilters(r *http.Request) (*FilterOptions, error) {
 		filters.MaxPrice = &maxPrice
 	}
 
+	if minCreditsStr := qs.Get("minCredits"); minCreditsStr != "" {
+		minCredits, err := strconv.ParseFloat(minCreditsStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minCredits: %v", err)
+		}
+		filters.MinCredits = &minCredits
+	}
+
+	if maxCreditsStr := qs.Get("maxCredits"); maxCreditsStr != "" {
+		maxCredits, err := strconv.ParseFloat(maxCreditsStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxCredits: %v", err)
+		}
+		filters.MaxCredits = &maxCredits
+	}
+
 	if biomeTypeStr := qs.Get("biomeType"); biomeTypeStr != "" {
 		filters.BiomeType = &biomeTypeStr
 	}
@@ -62,10 +115,150 @@ func getFilters(r *http.Request) (*FilterOptions, error) {
 		filters.Location = &locationStr
 	}
 
+	if minVintageStr := qs.Get("minVintageYear"); minVintageStr != "" {
+		minVintage, err := strconv.Atoi(minVintageStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minVintageYear: %v", err)
+		}
+		filters.MinVintageYear = &minVintage
+	}
+
+	if maxVintageStr := qs.Get("maxVintageYear"); maxVintageStr != "" {
+		maxVintage, err := strconv.Atoi(maxVintageStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maxVintageYear: %v", err)
+		}
+		filters.MaxVintageYear = &maxVintage
+	}
+
+	if standardStr := qs.Get("verificationStandard"); standardStr != "" {
+		filters.VerificationStandard = &standardStr
+	}
+
+	if expiresAfterStr := qs.Get("expiresAfter"); expiresAfterStr != "" {
+		expiresAfter, err := time.Parse(time.DateOnly, expiresAfterStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid expiresAfter: expected YYYY-MM-DD")
+		}
+		filters.ExpiresAfter = &expiresAfter
+	}
+
+	if statusStr := qs.Get("landVerificationStatus"); statusStr != "" {
+		switch statusStr {
+		case "pending", "verified", "rejected":
+		default:
+			return nil, fmt.Errorf("invalid landVerificationStatus: must be pending, verified or rejected")
+		}
+		filters.LandVerificationStatus = &statusStr
+	}
+
+	if densityStr := qs.Get("minForestDensity"); densityStr != "" {
+		density, err := strconv.ParseFloat(densityStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid minForestDensity: %v", err)
+		}
+		filters.MinForestDensity = &density
+	}
+
+	// Accepts repeated parameters as well as comma-separated lists
+	for _, speciesStr := range qs["treeSpecies"] {
+		for _, species := range strings.Split(speciesStr, ",") {
+			if species = strings.TrimSpace(species); species != "" {
+				filters.TreeSpecies = append(filters.TreeSpecies, species)
+			}
+		}
+	}
+
+	if queryStr := strings.TrimSpace(qs.Get("q")); queryStr != "" {
+		filters.Query = &queryStr
+	}
+
+	if nearStr := qs.Get("near"); nearStr != "" {
+		near, err := parseGeoPoint(nearStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid near: %v", err)
+		}
+		filters.Near = near
+	}
+
+	if radiusStr := qs.Get("radiusKm"); radiusStr != "" {
+		radius, err := strconv.ParseFloat(radiusStr, 64)
+		if err != nil || radius <= 0 {
+			return nil, fmt.Errorf("invalid radiusKm: must be a positive number")
+		}
+		if filters.Near == nil {
+			return nil, fmt.Errorf("radiusKm requires near")
+		}
+		filters.RadiusKm = &radius
+	}
+
+	if bboxStr := qs.Get("bbox"); bboxStr != "" {
+		bbox, err := parseBoundingBox(bboxStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bbox: %v", err)
+		}
+		filters.BBox = bbox
+	}
+
+	if sortStr := qs.Get("sort"); sortStr != "" {
+		if err := validateListingSort(sortStr, filters.Near); err != nil {
+			return nil, err
+		}
+		filters.Sort = &sortStr
+	}
+
 	// Return the filters options
 	return filters, nil
 }
 
+func getCandleQuery(r *http.Request) (CandleQuery, error) {
+	qs := r.URL.Query()
+
+	q := CandleQuery{Interval: "day"}
+
+	if interval := qs.Get("interval"); interval != "" {
+		q.Interval = interval
+	}
+
+	if biomeTypeStr := qs.Get("biomeType"); biomeTypeStr != "" {
+		q.BiomeType = &biomeTypeStr
+	}
+
+	if locationStr := qs.Get("location"); locationStr != "" {
+		q.Location = &locationStr
+	}
+
+	if vintageStr := qs.Get("vintageYear"); vintageStr != "" {
+		vintage, err := strconv.Atoi(vintageStr)
+		if err != nil {
+			return q, fmt.Errorf("invalid vintageYear: %v", err)
+		}
+		q.VintageYear = &vintage
+	}
+
+	if standardStr := qs.Get("verificationStandard"); standardStr != "" {
+		q.VerificationStandard = &standardStr
+	}
+
+	if fromStr := qs.Get("from"); fromStr != "" {
+		from, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			return q, fmt.Errorf("invalid from: %v", err)
+		}
+		q.From = &from
+	}
+
+	if toStr := qs.Get("to"); toStr != "" {
+		to, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			return q, fmt.Errorf("invalid to: %v", err)
+		}
+		q.To = &to
+	}
+
+	return q, nil
+}
+
 func (h *Handler) checkSellerRole(w http.ResponseWriter, r *http.Request) bool {
 	role := r.Header.Get("X-User-Role")
 	if role != "seller" {
@@ -76,6 +269,16 @@ func (h *Handler) checkSellerRole(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
+func (h *Handler) checkBuyerRole(w http.ResponseWriter, r *http.Request) bool {
+	role := r.Header.Get("X-User-Role")
+	if role != "buyer" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "unauthorized: buyer role required"})
+		return false
+	}
+	return true
+}
+
 func (h *Handler) getUserIDFromHeader(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
 	userIDStr := r.Header.Get("X-User-ID")
 	if userIDStr == "" {
@@ -111,9 +314,11 @@ func (h *Handler) handleHealthCheck(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Param page query integer false "Page number (default: 1)"
-// @Param limit query integer false "Number of items per page (default: 10)"
-// @Success 200 {array} Land
+// @Param cursor query string false "nextCursor from the previous page"
+// @Param limit query integer false "Number of items per page (default: 10, max: 100)"
+// @Param total query boolean false "Include the total number of listings"
+// @Success 200 {object} Page[CreditListing]
+// @Failure 400 {object} ErrorResponse "Invalid pagination parameters"
 // @Failure 401 {object} ErrorResponse "Unauthorized - Invalid or missing seller credentials"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/market/private [get]
@@ -130,10 +335,20 @@ func (h *Handler) handlePrivateListings(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	page, limit := getPaginationParams(r)
-	lands, err := h.svc.GetSellerListings(ctx, userID, page, limit)
+	page, err := getPageRequest(r)
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	lands, err := h.svc.GetSellerListings(ctx, userID, page)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrInvalidCursor {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
 		return
 	}
@@ -196,12 +411,28 @@ func (h *Handler) handlePrivateListingsByID(w http.ResponseWriter, r *http.Reque
 // @Tags listings
 // @Accept json
 // @Produce json
-// @Param page query integer false "Page number (default: 1)"
-// @Param limit query integer false "Number of items per page (default: 10)"
+// @Param cursor query string false "nextCursor from the previous page"
+// @Param limit query integer false "Number of items per page (default: 10, max: 100)"
+// @Param total query boolean false "Include the total number of matching listings"
 // @Param minPrice query number false "Minimum price filter"
 // @Param maxPrice query number false "Maximum price filter"
+// @Param biomeType query string false "Biome type filter"
 // @Param location query string false "Location filter"
-// @Success 200 {array} Land
+// @Param minCredits query number false "Minimum credits available"
+// @Param maxCredits query number false "Maximum credits available"
+// @Param minVintageYear query integer false "Earliest vintage year"
+// @Param maxVintageYear query integer false "Latest vintage year"
+// @Param verificationStandard query string false "Verification standard filter"
+// @Param expiresAfter query string false "Only credits that don't expire before this date (YYYY-MM-DD)"
+// @Param landVerificationStatus query string false "Land verification status: pending, verified or rejected"
+// @Param minForestDensity query number false "Minimum forest density percentage"
+// @Param treeSpecies query []string false "Any of these tree species" collectionFormat(multi)
+// @Param q query string false "Full-text search over land title, description, location and tree species; results are ranked by relevance"
+// @Param near query string false "Sort by distance from lat,lon"
+// @Param radiusKm query number false "With near, only listings within this many km"
+// @Param bbox query string false "Only listings inside minLon,minLat,maxLon,maxLat"
+// @Param sort query string false "Sort by price, createdAt, vintage, available or distance (requires near); prefix with - for descending"
+// @Success 200 {object} Page[CreditListing]
 // @Failure 400 {string} string "Invalid filters on request"
 // @Failure 500 {string} string "Internal server error"
 // @Failure 504 {string} string "Request timed out"
@@ -210,7 +441,12 @@ func (h *Handler) handleActiveListings(w http.ResponseWriter, r *http.Request) {
 	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
 	defer cancel()
 
-	page, limit := getPaginationParams(r)
+	page, err := getPageRequest(r)
+	if err != nil {
+		http.Error(w, "Invalid pagination on request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	filters, err := getFilters(r)
 
 	if err != nil {
@@ -218,9 +454,14 @@ func (h *Handler) handleActiveListings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	res, err := h.svc.GetActiveListings(ctx, filters, page, limit)
+	res, err := h.svc.GetActiveListings(ctx, filters, page)
 
 	if err != nil {
+		if err == ErrInvalidCursor {
+			http.Error(w, "Invalid pagination on request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		if errors.Is(err, context.DeadlineExceeded) { // Check if the error is due to timeout
 			http.Error(w, "Request timed out", http.StatusGatewayTimeout)
 			return
@@ -275,7 +516,7 @@ func (h *Handler) handleActiveListingsByID(w http.ResponseWriter, r *http.Reques
 
 // handleCreateListing godoc
 // @Summary Create a new credit listing
-// @Description Creates a new credit listing for a seller's carbon credits
+// @Description Creates a new credit listing for a seller's carbon credits, allocating the given quantity from the batch
 // @Tags listings
 // @Accept json
 // @Produce json
@@ -285,6 +526,7 @@ func (h *Handler) handleActiveListingsByID(w http.ResponseWriter, r *http.Reques
 // @Success 201 {object} CreditListing "Created listing"
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Not enough unallocated credits in the batch, or an active listing's land or seller is unverified or its credits expired"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/market/private [post]
 func (h *Handler) handleCreateListing(w http.ResponseWriter, r *http.Request) {
@@ -307,8 +549,13 @@ func (h *Handler) handleCreateListing(w http.ResponseWriter, r *http.Request) {
 	listing, err := h.svc.CreateListing(r.Context(), userID, req)
 	if err != nil {
 		status := http.StatusInternalServerError
-		if err == ErrUnauthorized {
+		switch err {
+		case ErrUnauthorized:
 			status = http.StatusUnauthorized
+		case ErrInvalidStatus, ErrInvalidListing:
+			status = http.StatusBadRequest
+		case ErrInsufficientCredits, ErrLandNotVerified, ErrSellerNotVerified, ErrCreditsExpired:
+			status = http.StatusConflict
 		}
 		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
@@ -333,6 +580,7 @@ func (h *Handler) handleCreateListing(w http.ResponseWriter, r *http.Request) {
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 404 {object} ErrorResponse "Listing not found"
+// @Failure 409 {object} ErrorResponse "Status transition not allowed, listing is sold or cancelled, not enough unallocated credits in the batch, or land or seller unverified or credits expired on activation"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/market/private/{id} [put]
 func (h *Handler) handleUpdateListing(w http.ResponseWriter, r *http.Request) {
@@ -367,6 +615,10 @@ func (h *Handler) handleUpdateListing(w http.ResponseWriter, r *http.Request) {
 			status = http.StatusNotFound
 		case ErrUnauthorized:
 			status = http.StatusUnauthorized
+		case ErrInvalidListing:
+			status = http.StatusBadRequest
+		case ErrInvalidTransition, ErrInsufficientCredits, ErrLandNotVerified, ErrSellerNotVerified, ErrCreditsExpired:
+			status = http.StatusConflict
 		}
 		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
@@ -377,8 +629,8 @@ func (h *Handler) handleUpdateListing(w http.ResponseWriter, r *http.Request) {
 }
 
 // handleDeleteListing godoc
-// @Summary Delete a credit listing
-// @Description Deletes an existing credit listing owned by the seller
+// @Summary Delete a draft listing
+// @Description Deletes one of the seller's draft listings that was never published. Published listings have to be cancelled instead
 // @Tags listings
 // @Produce json
 // @Param X-User-ID header string true "User ID"
@@ -388,6 +640,7 @@ func (h *Handler) handleUpdateListing(w http.ResponseWriter, r *http.Request) {
 // @Failure 400 {object} ErrorResponse "Invalid request"
 // @Failure 401 {object} ErrorResponse "Unauthorized"
 // @Failure 404 {object} ErrorResponse "Listing not found"
+// @Failure 409 {object} ErrorResponse "Listing is not an unpublished draft"
 // @Failure 500 {object} ErrorResponse "Internal server error"
 // @Router /api/market/private/{id} [delete]
 func (h *Handler) handleDeleteListing(w http.ResponseWriter, r *http.Request) {
@@ -409,8 +662,155 @@ func (h *Handler) handleDeleteListing(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.svc.DeleteListing(r.Context(), userID, listingID); err != nil {
 		status := http.StatusInternalServerError
-		if err == ErrNotFound {
+		switch err {
+		case ErrNotFound:
+			status = http.StatusNotFound
+		case ErrInvalidTransition:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePublishListing godoc
+// @Summary Publish a draft listing
+// @Description Moves one of the seller's draft listings to active
+// @Tags listings
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'seller')"
+// @Param id path string true "Listing ID" format(uuid)
+// @Success 200 {object} CreditListing "Published listing"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Listing not found"
+// @Failure 409 {object} ErrorResponse "Listing is not a draft, has no quantity allocated, its land or seller is unverified or its credits expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/{id}/publish [post]
+func (h *Handler) handlePublishListing(w http.ResponseWriter, r *http.Request) {
+	h.transitionListing(w, r, h.svc.PublishListing)
+}
+
+// handleCancelListing godoc
+// @Summary Cancel a listing
+// @Description Withdraws one of the seller's draft or active listings and releases any credits held on it
+// @Tags listings
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'seller')"
+// @Param id path string true "Listing ID" format(uuid)
+// @Success 200 {object} CreditListing "Cancelled listing"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Listing not found"
+// @Failure 409 {object} ErrorResponse "Listing is already sold or cancelled"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/{id}/cancel [post]
+func (h *Handler) handleCancelListing(w http.ResponseWriter, r *http.Request) {
+	h.transitionListing(w, r, h.svc.CancelListing)
+}
+
+// handleCreditAvailability godoc
+// @Summary Check unallocated credits
+// @Description Checks whether the given amount of one of the seller's credit batches is still free to allocate to a listing or auction
+// @Tags listings
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'seller')"
+// @Param id path string true "Carbon credit batch ID" format(uuid)
+// @Param amount query number true "Credits to allocate"
+// @Success 204 "Credits are available"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Credit batch not found"
+// @Failure 409 {object} ErrorResponse "Not enough unallocated credits"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/credits/{id}/availability [get]
+func (h *Handler) handleCreditAvailability(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkSellerRole(w, r) {
+		return
+	}
+
+	creditID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid credit ID"})
+		return
+	}
+
+	amount, err := strconv.ParseFloat(r.URL.Query().Get("amount"), 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid amount"})
+		return
+	}
+
+	if err := h.svc.CheckCreditAvailability(r.Context(), userID, creditID, amount); err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrInvalidAmount:
+			status = http.StatusBadRequest
+		case ErrCreditNotFound:
+			status = http.StatusNotFound
+		case ErrInsufficientCredits:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleLandVerification godoc
+// @Summary Check whether a land can be listed
+// @Description Checks that one of the seller's lands and the seller are both verified, so credits from it can be listed
+// @Tags listings
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'seller')"
+// @Param id path string true "Land ID" format(uuid)
+// @Success 204 "Land and seller are verified"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Land not found"
+// @Failure 409 {object} ErrorResponse "Land or seller is not verified"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/lands/{id}/verification [get]
+func (h *Handler) handleLandVerification(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkSellerRole(w, r) {
+		return
+	}
+
+	landID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid land ID"})
+		return
+	}
+
+	if err := h.svc.CheckLandVerification(r.Context(), userID, landID); err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrLandNotFound:
 			status = http.StatusNotFound
+		case ErrLandNotVerified, ErrSellerNotVerified:
+			status = http.StatusConflict
 		}
 		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
@@ -419,3 +819,1218 @@ func (h *Handler) handleDeleteListing(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func (h *Handler) transitionListing(w http.ResponseWriter, r *http.Request, transition func(ctx context.Context, userID, listingID uuid.UUID) (*CreditListing, error)) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkSellerRole(w, r) {
+		return
+	}
+
+	listingID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid listing ID"})
+		return
+	}
+
+	listing, err := transition(r.Context(), userID, listingID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrNotFound:
+			status = http.StatusNotFound
+		case ErrInvalidTransition, ErrInvalidListing, ErrLandNotVerified, ErrSellerNotVerified, ErrCreditsExpired:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(listing)
+}
+
+// handlePlaceOrder godoc
+// @Summary Buy credits from an active listing
+// @Description Charges the buyer and purchases an amount of credits from an active listing, adding them to the buyer's wallet
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Listing ID" format(uuid)
+// @Param order body PlaceOrderRequest true "Order request"
+// @Success 201 {object} Purchase "Created purchase"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Listing not found"
+// @Failure 402 {object} ErrorResponse "Payment failed"
+// @Failure 409 {object} ErrorResponse "Not enough credits available, or the land or seller is unverified or the credits expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/active/{id}/orders [post]
+func (h *Handler) handlePlaceOrder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	listingID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid listing ID"})
+		return
+	}
+
+	var req PlaceOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	purchase, err := h.svc.PlaceOrder(r.Context(), userID, listingID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrNotFound:
+			status = http.StatusNotFound
+		case ErrInvalidAmount:
+			status = http.StatusBadRequest
+		case ErrInsufficientCredits, ErrLandNotVerified, ErrSellerNotVerified, ErrCreditsExpired:
+			status = http.StatusConflict
+		case ErrPaymentFailed:
+			status = http.StatusPaymentRequired
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(purchase)
+}
+
+// @Summary Get active auctions
+// @Description Retrieves a paginated list of auctions currently open for bidding, ending soonest first
+// @Tags auctions
+// @Accept json
+// @Produce json
+// @Param page query integer false "Page number (default: 1)"
+// @Param limit query integer false "Number of items per page (default: 10)"
+// @Param minPrice query number false "Minimum starting price filter"
+// @Param maxPrice query number false "Maximum starting price filter"
+// @Param biomeType query string false "Biome type filter"
+// @Param location query string false "Location filter"
+// @Param minCredits query number false "Minimum credits available"
+// @Param maxCredits query number false "Maximum credits available"
+// @Param minVintageYear query integer false "Earliest vintage year"
+// @Param maxVintageYear query integer false "Latest vintage year"
+// @Param verificationStandard query string false "Verification standard filter"
+// @Param expiresAfter query string false "Only credits that don't expire before this date (YYYY-MM-DD)"
+// @Param landVerificationStatus query string false "Land verification status: pending, verified or rejected"
+// @Param minForestDensity query number false "Minimum forest density percentage"
+// @Param treeSpecies query []string false "Any of these tree species" collectionFormat(multi)
+// @Param q query string false "Full-text search over land title, description, location and tree species"
+// @Param near query string false "Centre point lat,lon for radiusKm"
+// @Param radiusKm query number false "With near, only auctions within this many km"
+// @Param bbox query string false "Only auctions inside minLon,minLat,maxLon,maxLat"
+// @Success 200 {array} CreditAuction
+// @Failure 400 {string} string "Invalid filters on request, including any sort"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 504 {string} string "Request timed out"
+// @Router /api/market/auctions [get]
+func (h *Handler) handleActiveAuctions(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	page, limit := getPaginationParams(r)
+	filters, err := getFilters(r)
+
+	if err != nil {
+		http.Error(w, "Invalid filters on request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Auctions are always listed ending soonest first
+	if filters.Sort != nil {
+		http.Error(w, "Invalid filters on request: sort is not supported for auctions", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.svc.GetActiveAuctions(ctx, filters, page, limit)
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) { // Check if the error is due to timeout
+			http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+			return
+		}
+
+		http.Error(w, "Failed to get active auctions: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// @Summary Get auction by ID
+// @Description Retrieves an auction and its bids, highest first. Bids on sealed auctions are hidden until settlement
+// @Tags auctions
+// @Accept json
+// @Produce json
+// @Param id path string true "Auction ID (UUID format)"
+// @Success 200 {object} CreditAuction
+// @Failure 400 {string} string "Invalid auction ID"
+// @Failure 404 {string} string "Auction not found"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 504 {string} string "Request timed out"
+// @Router /api/market/auctions/{id} [get]
+func (h *Handler) handleAuctionByID(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	auctionID, err := uuid.Parse(r.PathValue("id"))
+
+	if err != nil {
+		http.Error(w, "Invalid auction ID", http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.svc.GetAuctionByID(ctx, auctionID)
+
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) { // Check if the error is due to timeout
+			http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+			return
+		}
+		if errors.Is(err, ErrAuctionNotFound) {
+			http.Error(w, "Auction not found", http.StatusNotFound)
+			return
+		}
+
+		http.Error(w, "Failed to get auction: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// handleCreateAuction godoc
+// @Summary Create a new credit auction
+// @Description Creates an auction for a seller's carbon credits, setting the lot quantity aside from the batch
+// @Tags auctions
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'seller')"
+// @Param auction body CreateAuctionRequest true "Auction creation request"
+// @Success 201 {object} CreditAuction "Created auction"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 409 {object} ErrorResponse "Not enough unallocated credits in the batch, or land or seller unverified or credits expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/auctions [post]
+func (h *Handler) handleCreateAuction(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkSellerRole(w, r) {
+		return
+	}
+
+	var req CreateAuctionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	auction, err := h.svc.CreateAuction(r.Context(), userID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrInvalidAuction:
+			status = http.StatusBadRequest
+		case ErrUnauthorized:
+			status = http.StatusUnauthorized
+		case ErrInsufficientCredits, ErrLandNotVerified, ErrSellerNotVerified, ErrCreditsExpired:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(auction)
+}
+
+// handlePlaceBid godoc
+// @Summary Place a bid on an auction
+// @Description Places a bid per credit. English auctions need the starting price or the highest bid plus the minimum increment, sealed auctions the starting price, and Dutch auctions are won outright by a bid at or above the current price
+// @Tags auctions
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Auction ID" format(uuid)
+// @Param bid body PlaceBidRequest true "Bid request"
+// @Success 201 {object} AuctionBid "Created bid"
+// @Failure 400 {object} ErrorResponse "Invalid request or bid too low"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} ErrorResponse "Payment for a Dutch auction lot failed"
+// @Failure 404 {object} ErrorResponse "Auction not found"
+// @Failure 409 {object} ErrorResponse "Auction is not open for bidding, or a Dutch auction lot's land or seller is unverified or its credits expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/auctions/{id}/bids [post]
+func (h *Handler) handlePlaceBid(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	auctionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid auction ID"})
+		return
+	}
+
+	var req PlaceBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	bid, err := h.svc.PlaceBid(r.Context(), userID, auctionID, req.Amount)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrAuctionNotFound:
+			status = http.StatusNotFound
+		case ErrBidTooLow:
+			status = http.StatusBadRequest
+		case ErrAuctionClosed, ErrUnsupportedBid, ErrLandNotVerified, ErrSellerNotVerified, ErrCreditsExpired:
+			status = http.StatusConflict
+		case ErrPaymentFailed:
+			status = http.StatusPaymentRequired
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(bid)
+}
+
+// handleSetProxyBid godoc
+// @Summary Set a proxy bid on an auction
+// @Description Stores the bidder's maximum bid; the service then bids on their behalf by the auction's minimum increment up to that cap
+// @Tags auctions
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Auction ID" format(uuid)
+// @Param proxy body ProxyBidRequest true "Proxy bid request"
+// @Success 200 {object} AuctionProxyBid "Stored proxy bid"
+// @Failure 400 {object} ErrorResponse "Invalid request or maximum too low"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Auction not found"
+// @Failure 409 {object} ErrorResponse "Auction is not open for bidding or not an English auction"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/auctions/{id}/proxy [put]
+func (h *Handler) handleSetProxyBid(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	auctionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid auction ID"})
+		return
+	}
+
+	var req ProxyBidRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	proxy, err := h.svc.SetProxyBid(r.Context(), userID, auctionID, req.MaxAmount)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrAuctionNotFound:
+			status = http.StatusNotFound
+		case ErrBidTooLow:
+			status = http.StatusBadRequest
+		case ErrAuctionClosed, ErrUnsupportedBid:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(proxy)
+}
+
+// handleCreateBuyOrder godoc
+// @Summary Create a standing buy order
+// @Description Creates a limit order that the matching engine fills against active listings at or below the maximum price
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param order body CreateBuyOrderRequest true "Buy order request"
+// @Success 201 {object} BuyOrder "Created buy order"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/buy-orders [post]
+func (h *Handler) handleCreateBuyOrder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	var req CreateBuyOrderRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	order, err := h.svc.CreateBuyOrder(r.Context(), userID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrInvalidBuyOrder {
+			status = http.StatusBadRequest
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(order)
+}
+
+// @Summary Get buyer's buy orders
+// @Description Retrieves a paginated list of the authenticated buyer's buy orders, newest first
+// @Tags orders
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param page query integer false "Page number (default: 1)"
+// @Param limit query integer false "Number of items per page (default: 10)"
+// @Success 200 {array} BuyOrder
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/buy-orders [get]
+func (h *Handler) handleBuyOrders(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	page, limit := getPaginationParams(r)
+	orders, err := h.svc.GetBuyOrders(ctx, userID, page, limit)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(orders)
+}
+
+// handleCancelBuyOrder godoc
+// @Summary Cancel a buy order
+// @Description Cancels the unfilled remainder of an open buy order; fills already made are kept
+// @Tags orders
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Buy order ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Buy order not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/buy-orders/{id} [delete]
+func (h *Handler) handleCancelBuyOrder(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	orderID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid buy order ID"})
+		return
+	}
+
+	if err := h.svc.CancelBuyOrder(r.Context(), userID, orderID); err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrBuyOrderNotFound {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleReserveCredits godoc
+// @Summary Hold credits for checkout
+// @Description Holds an amount of a listing's credits at the current price for the configured TTL, so no one else can buy them while the buyer pays
+// @Tags orders
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Listing ID" format(uuid)
+// @Param reservation body ReserveCreditsRequest true "Reservation request"
+// @Success 201 {object} CreditReservation "Created reservation"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Listing not found"
+// @Failure 409 {object} ErrorResponse "Not enough credits available, or the land or seller is unverified or the credits expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/active/{id}/reservations [post]
+func (h *Handler) handleReserveCredits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	listingID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid listing ID"})
+		return
+	}
+
+	var req ReserveCreditsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	reservation, err := h.svc.ReserveCredits(r.Context(), userID, listingID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrNotFound:
+			status = http.StatusNotFound
+		case ErrInvalidAmount:
+			status = http.StatusBadRequest
+		case ErrInsufficientCredits, ErrLandNotVerified, ErrSellerNotVerified, ErrCreditsExpired:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(reservation)
+}
+
+// handleConfirmReservation godoc
+// @Summary Confirm a checkout reservation
+// @Description Confirms the hold's payment intent and turns the hold into a purchase at the reserved price
+// @Tags orders
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Reservation ID" format(uuid)
+// @Success 201 {object} Purchase "Created purchase"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 402 {object} ErrorResponse "Payment failed"
+// @Failure 404 {object} ErrorResponse "Reservation or listing not found"
+// @Failure 409 {object} ErrorResponse "Not enough credits available"
+// @Failure 410 {object} ErrorResponse "Reservation has expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/reservations/{id}/confirm [post]
+func (h *Handler) handleConfirmReservation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	reservationID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid reservation ID"})
+		return
+	}
+
+	purchase, err := h.svc.ConfirmReservation(r.Context(), userID, reservationID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrReservationNotFound, ErrNotFound:
+			status = http.StatusNotFound
+		case ErrInsufficientCredits:
+			status = http.StatusConflict
+		case ErrReservationExpired:
+			status = http.StatusGone
+		case ErrPaymentFailed:
+			status = http.StatusPaymentRequired
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(purchase)
+}
+
+// handleReleaseReservation godoc
+// @Summary Release a checkout reservation
+// @Description Gives held credits back to the market before the hold expires
+// @Tags orders
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Reservation ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Reservation not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/reservations/{id} [delete]
+func (h *Handler) handleReleaseReservation(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	reservationID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid reservation ID"})
+		return
+	}
+
+	if err := h.svc.ReleaseReservation(r.Context(), userID, reservationID); err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrReservationNotFound {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handlePaymentWebhook godoc
+// @Summary Receive payment provider webhooks
+// @Description Applies asynchronous payment outcomes to checkout reservations. The request must carry the provider's signature
+// @Tags payments
+// @Accept json
+// @Produce json
+// @Param X-Payment-Signature header string true "Provider signature of the raw body"
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid payload"
+// @Failure 401 {object} ErrorResponse "Invalid signature"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/payments/webhook [post]
+func (h *Handler) handlePaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	if err := h.svc.HandlePaymentWebhook(r.Context(), payload, r.Header.Get("X-Payment-Signature")); err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrInvalidWebhookSignature {
+			status = http.StatusUnauthorized
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// handleRefundPurchase godoc
+// @Summary Cancel a purchase for a refund
+// @Description Reverses a purchase within the refund grace period, returning the credits to the market and refunding the payment. If the gateway refund fails it is retried in the background until PaymentRefundedAt is set. Refused once any of the credits were retired or transferred
+// @Tags orders
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Purchase ID" format(uuid)
+// @Success 200 {object} Purchase "Refunded purchase"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Purchase not found"
+// @Failure 409 {object} ErrorResponse "Grace period passed or credits already used"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/purchases/{id}/refund [post]
+func (h *Handler) handleRefundPurchase(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	purchaseID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid purchase ID"})
+		return
+	}
+
+	purchase, err := h.svc.RefundPurchase(r.Context(), userID, purchaseID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrPurchaseNotFound:
+			status = http.StatusNotFound
+		case ErrRefundWindowClosed, ErrCreditsInUse:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(purchase)
+}
+
+// handleWalletHoldings godoc
+// @Summary Get buyer's wallet holdings
+// @Description Lists the authenticated buyer's credits grouped by vintage year, biome and originating land, with purchased, remaining and retired quantities and the average acquisition price
+// @Tags wallet
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Success 200 {array} WalletHolding
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/wallets [get]
+func (h *Handler) handleWalletHoldings(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	holdings, err := h.svc.GetWalletHoldings(ctx, userID)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	json.NewEncoder(w).Encode(holdings)
+}
+
+// handleTransferCredits godoc
+// @Summary Transfer credits to another buyer
+// @Description Moves credits from one of the authenticated buyer's wallet entries to a new wallet entry of another buyer without a sale, keeping the link to the original purchase. Retired or resale-reserved credits cannot be transferred
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Wallet entry ID" format(uuid)
+// @Param transfer body TransferCreditsRequest true "Transfer request"
+// @Success 201 {object} CreditTransfer "Created transfer"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Wallet entry or recipient not found"
+// @Failure 409 {object} ErrorResponse "Not enough credits remaining"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/wallets/{id}/transfers [post]
+func (h *Handler) handleTransferCredits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	walletID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid wallet ID"})
+		return
+	}
+
+	var req TransferCreditsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	transfer, err := h.svc.TransferCredits(r.Context(), userID, walletID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrWalletNotFound, ErrRecipientNotFound:
+			status = http.StatusNotFound
+		case ErrInvalidTransfer:
+			status = http.StatusBadRequest
+		case ErrInsufficientCredits:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(transfer)
+}
+
+// handleRetireCredits godoc
+// @Summary Retire credits from a wallet entry
+// @Description Permanently retires credits on behalf of a beneficiary and records an immutable retirement tied to the originating purchase and vintage
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Wallet entry ID" format(uuid)
+// @Param retirement body RetireCreditsRequest true "Retirement request"
+// @Success 201 {object} CreditRetirement "Created retirement"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Wallet entry not found"
+// @Failure 409 {object} ErrorResponse "Not enough credits remaining"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/wallets/{id}/retire [post]
+func (h *Handler) handleRetireCredits(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	walletID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid wallet ID"})
+		return
+	}
+
+	var req RetireCreditsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	retirement, err := h.svc.RetireCredits(r.Context(), userID, walletID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrWalletNotFound:
+			status = http.StatusNotFound
+		case ErrInvalidRetirement:
+			status = http.StatusBadRequest
+		case ErrInsufficientCredits:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(retirement)
+}
+
+// handleCreateResaleListing godoc
+// @Summary Relist credits from a wallet entry
+// @Description Puts credits from one of the authenticated buyer's wallet entries up for sale on the secondary market. The listed quantity is reserved from the entry's remaining credits until it sells or the listing is cancelled
+// @Tags wallet
+// @Accept json
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Wallet entry ID" format(uuid)
+// @Param listing body CreateResaleListingRequest true "Resale listing request"
+// @Success 201 {object} CreditListing "Created resale listing"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Wallet entry not found"
+// @Failure 409 {object} ErrorResponse "Not enough credits remaining, land or seller unverified, or credits expired"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/wallets/{id}/listings [post]
+func (h *Handler) handleCreateResaleListing(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	walletID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid wallet ID"})
+		return
+	}
+
+	var req CreateResaleListingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	listing, err := h.svc.CreateResaleListing(r.Context(), userID, walletID, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err {
+		case ErrWalletNotFound:
+			status = http.StatusNotFound
+		case ErrInvalidListing:
+			status = http.StatusBadRequest
+		case ErrInsufficientCredits, ErrLandNotVerified, ErrSellerNotVerified, ErrCreditsExpired:
+			status = http.StatusConflict
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(listing)
+}
+
+// handleCancelResaleListing godoc
+// @Summary Cancel a resale listing
+// @Description Withdraws one of the authenticated buyer's resale listings and returns its unsold credits to the wallet entry they came from
+// @Tags wallet
+// @Produce json
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Listing ID" format(uuid)
+// @Success 204 "No Content"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Listing not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/resale/{id} [delete]
+func (h *Handler) handleCancelResaleListing(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	listingID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid listing ID"})
+		return
+	}
+
+	if err := h.svc.CancelResaleListing(r.Context(), userID, listingID); err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrNotFound {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// @Summary Download a retirement certificate
+// @Description Renders the PDF certificate for one of the authenticated buyer's retirements
+// @Tags wallet
+// @Produce application/pdf
+// @Param X-User-ID header string true "User ID"
+// @Param X-User-Role header string true "User Role (must be 'buyer')"
+// @Param id path string true "Retirement ID" format(uuid)
+// @Success 200 {file} file "PDF certificate"
+// @Failure 400 {object} ErrorResponse "Invalid request"
+// @Failure 401 {object} ErrorResponse "Unauthorized"
+// @Failure 404 {object} ErrorResponse "Retirement not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/private/retirements/{id}/certificate [get]
+func (h *Handler) handleRetirementCertificate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := h.getUserIDFromHeader(w, r)
+	if !ok {
+		return
+	}
+
+	if !h.checkBuyerRole(w, r) {
+		return
+	}
+
+	retirementID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: "invalid retirement ID"})
+		return
+	}
+
+	retirement, err := h.svc.GetRetirement(r.Context(), userID, retirementID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrRetirementNotFound {
+			status = http.StatusNotFound
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writeRetirementCertificate(&buf, retirement); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", retirement.CertificateID+".pdf"))
+	w.Write(buf.Bytes())
+}
+
+// @Summary Verify a retirement certificate
+// @Description Public endpoint confirming that a certificate ID belongs to a genuine retirement
+// @Tags certificates
+// @Produce json
+// @Param certificateId path string true "Certificate ID"
+// @Success 200 {object} CertificateVerification
+// @Failure 404 {object} ErrorResponse "Certificate not found"
+// @Failure 500 {object} ErrorResponse "Internal server error"
+// @Router /api/market/certificates/{certificateId} [get]
+func (h *Handler) handleVerifyCertificate(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	res, err := h.svc.VerifyCertificate(ctx, r.PathValue("certificateId"))
+	if err != nil {
+		status := http.StatusInternalServerError
+		if err == ErrRetirementNotFound {
+			status = http.StatusNotFound
+			err = errors.New("certificate not found")
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// @Summary Get price candles
+// @Description Returns open/high/low/close/volume buckets built from completed purchases, oldest first, with optional filters
+// @Tags stats
+// @Produce json
+// @Param interval query string false "Bucket size: day, week or month (default: day)"
+// @Param biomeType query string false "Biome type filter"
+// @Param location query string false "Location filter"
+// @Param vintageYear query integer false "Vintage year filter"
+// @Param verificationStandard query string false "Verification standard filter"
+// @Param from query string false "Only purchases at or after this time (RFC 3339)"
+// @Param to query string false "Only purchases before this time (RFC 3339)"
+// @Success 200 {array} Candle
+// @Failure 400 {string} string "Invalid query parameters"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 504 {string} string "Request timed out"
+// @Router /api/market/stats/candles [get]
+func (h *Handler) handlePriceCandles(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	q, err := getCandleQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res, err := h.svc.GetPriceCandles(ctx, q)
+	if err != nil {
+		if err == ErrInvalidInterval {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+			return
+		}
+
+		http.Error(w, "Failed to get price candles: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// @Summary Get market summary
+// @Description Returns marketplace totals for the dashboard: active listings, credits on offer, traded volume and VWAP over the last 24 hours, 7 days and 30 days, and the best ask per biome. The summary is cached briefly
+// @Tags stats
+// @Produce json
+// @Success 200 {object} MarketSummary
+// @Failure 500 {string} string "Internal server error"
+// @Failure 504 {string} string "Request timed out"
+// @Router /api/market/stats/summary [get]
+func (h *Handler) handleMarketSummary(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+	defer cancel()
+
+	res, err := h.svc.GetMarketSummary(ctx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+			return
+		}
+
+		http.Error(w, "Failed to get market summary: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(res)
+}
+
+// @Summary Export lands as GeoJSON
+// @Description Returns lands as a GeoJSON FeatureCollection of points with land properties and the best current price per credit, for GIS tools such as QGIS
+// @Tags lands
+// @Produce json
+// @Param activeOnly query boolean false "Only lands with at least one active listing"
+// @Param bbox query string false "Only lands inside minLon,minLat,maxLon,maxLat"
+// @Success 200 {object} LandFeatureCollection
+// @Failure 400 {string} string "Invalid query parameters"
+// @Failure 500 {string} string "Internal server error"
+// @Failure 504 {string} string "Request timed out"
+// @Router /api/market/lands/geojson [get]
+func (h *Handler) handleLandFeatures(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+
+	qs := r.URL.Query()
+
+	activeOnly := false
+	if activeOnlyStr := qs.Get("activeOnly"); activeOnlyStr != "" {
+		var err error
+		if activeOnly, err = strconv.ParseBool(activeOnlyStr); err != nil {
+			http.Error(w, "invalid activeOnly: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	var bbox *BoundingBox
+	if bboxStr := qs.Get("bbox"); bboxStr != "" {
+		var err error
+		if bbox, err = parseBoundingBox(bboxStr); err != nil {
+			http.Error(w, "invalid bbox: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	res, err := h.svc.GetLandFeatures(ctx, activeOnly, bbox)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) {
+			http.Error(w, "Request timed out", http.StatusGatewayTimeout)
+			return
+		}
+
+		http.Error(w, "Failed to export lands: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	json.NewEncoder(w).Encode(res)
+}
@@ -3,41 +3,48 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MarketSVC struct {
-	db *gorm.DB
+	db       *gorm.DB
+	cfg      MarketConfig
+	payments PaymentProvider
+	summary  summaryCache
 }
 
-func NewMarketSVC(db *gorm.DB) MarketplaceService {
-	return &MarketSVC{db: db}
+func NewMarketSVC(db *gorm.DB, cfg MarketConfig, payments PaymentProvider) MarketplaceService {
+	return &MarketSVC{db: db, cfg: cfg, payments: payments}
 }
 
 // TODO missing filtering
-func (s *MarketSVC) GetSellerListings(ctx context.Context, userID uuid.UUID, page, limit int) ([]CreditListing, error) {
-	var listings []CreditListing
-	offset := (page - 1) * limit
-
+func (s *MarketSVC) GetSellerListings(ctx context.Context, userID uuid.UUID, page PageRequest) (*Page[CreditListing], error) {
 	query := s.db.WithContext(ctx).
-		Preload("CarbonCredit").
-		Preload("CarbonCredit.Land").
-		Preload("CarbonCredit.Land.Seller").
+		Model(&CreditListing{}).
 		Joins("JOIN carbon_credits ON credit_listings.carbon_credits_id = carbon_credits.id").
 		Joins("JOIN lands ON carbon_credits.land_id = lands.id").
 		Joins("JOIN sellers ON lands.owner_id = sellers.id").
-		Where("sellers.user_id = ?", userID).
-		Offset(offset).
-		Limit(limit)
+		Where("sellers.user_id = ? AND credit_listings.wallet_id IS NULL", userID)
 
-	if err := query.Find(&listings).Error; err != nil {
-		return nil, err
+	sort := keysetSort{
+		key:      "-createdAt",
+		expr:     clause.Expr{SQL: "credit_listings.created_at"},
+		exprType: "timestamptz",
+		desc:     true,
+		idColumn: "credit_listings.id",
 	}
 
-	return listings, nil
+	return findPage(query, sort, page, func(l *CreditListing) uuid.UUID { return l.ID },
+		"CarbonCredit", "CarbonCredit.Land", "CarbonCredit.Land.Seller")
 }
 
 func (s *MarketSVC) GetSellerListingByID(ctx context.Context, userID uuid.UUID, listingID uuid.UUID) (*CreditListing, error) {
@@ -50,7 +57,7 @@ func (s *MarketSVC) GetSellerListingByID(ctx context.Context, userID uuid.UUID,
 		Joins("JOIN carbon_credits ON credit_listings.carbon_credits_id = carbon_credits.id").
 		Joins("JOIN lands ON carbon_credits.land_id = lands.id").
 		Joins("JOIN sellers ON lands.owner_id = sellers.id").
-		Where("sellers.user_id = ? and credit_listings.id = ?", userID, listingID)
+		Where("sellers.user_id = ? and credit_listings.id = ? AND credit_listings.wallet_id IS NULL", userID, listingID)
 
 	if err := query.Find(&listing).Error; err != nil {
 		return nil, err
@@ -59,38 +66,92 @@ func (s *MarketSVC) GetSellerListingByID(ctx context.Context, userID uuid.UUID,
 	return &listing, nil
 }
 
-func (s *MarketSVC) GetActiveListings(ctx context.Context, filter *FilterOptions, page, limit int) ([]CreditListing, error) {
-	var listings []CreditListing
-	query := s.db.WithContext(ctx).Model(&CreditListing{}).Offset((page-1)*limit).Limit(limit).Where("status = ?", "active")
+func (s *MarketSVC) GetActiveListings(ctx context.Context, filter *FilterOptions, page PageRequest) (*Page[CreditListing], error) {
+	sort, err := listingSort(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sort values and cursors may refer to the batch or land, so they are
+	// always joined
+	q := newListingQuery(s.db.WithContext(ctx).Model(&CreditListing{})).apply(filter).joinLands()
+	query := q.db.Where("credit_listings.status = ?", "active")
 
-	query = query.Preload("CarbonCredit").Preload("CarbonCredit.Land").Preload("CarbonCredit.Land.Seller")
+	return findPage(query, sort, page, func(l *CreditListing) uuid.UUID { return l.ID },
+		"CarbonCredit", "CarbonCredit.Land", "CarbonCredit.Land.Seller")
+}
 
-	// Apply filters
-	if filter != nil {
-		if filter.MinPrice != nil {
-			query = query.Where("price_per_credit >= ?", *filter.MinPrice)
-		}
-		if filter.MaxPrice != nil {
-			query = query.Where("price_per_credit <= ?", *filter.MaxPrice)
+// listingSorts maps the accepted sort keys to the expression they order by
+// and its SQL type. Distance is computed from the filter's near point.
+var listingSorts = map[string]struct{ expr, exprType string }{
+	"price":     {"credit_listings.price_per_credit", "numeric"},
+	"createdAt": {"credit_listings.created_at", "timestamptz"},
+	"vintage":   {"carbon_credits.vintage_year", "integer"},
+	"available": {"credit_listings.quantity", "numeric"},
+	"distance":  {"", "double precision"},
+}
+
+// validateListingSort checks a sort key, optionally prefixed with - for
+// descending order.
+func validateListingSort(sort string, near *GeoPoint) error {
+	key := strings.TrimPrefix(sort, "-")
+	if _, ok := listingSorts[key]; !ok {
+		return ErrInvalidSort
+	}
+	if key == "distance" && near == nil {
+		return ErrInvalidSort
+	}
+	return nil
+}
+
+// listingSort returns how active listings are ordered: by the requested
+// sort, otherwise by distance when near is given, relevance when q is given,
+// and newest first. Listing IDs break ties so pages don't overlap.
+func listingSort(filter *FilterOptions) (keysetSort, error) {
+	if filter == nil {
+		filter = &FilterOptions{}
+	}
+
+	sort := keysetSort{idColumn: "credit_listings.id"}
+
+	switch {
+	case filter.Sort != nil:
+		if err := validateListingSort(*filter.Sort, filter.Near); err != nil {
+			return sort, err
 		}
-		if filter.BiomeType != nil {
-			query = query.Joins("JOIN carbon_credits ON carbon_credits.id = credit_listings.carbon_credits_id").
-				Joins("JOIN lands ON lands.id = carbon_credits.land_id").
-				Where("lands.biome_type = ?", *filter.BiomeType)
+
+		key := strings.TrimPrefix(*filter.Sort, "-")
+		sort.key = *filter.Sort
+		sort.desc = strings.HasPrefix(*filter.Sort, "-")
+		sort.expr = clause.Expr{SQL: listingSorts[key].expr}
+		sort.exprType = listingSorts[key].exprType
+		if key == "distance" {
+			sort.key += fmt.Sprintf(":%g,%g", filter.Near.Lat, filter.Near.Lon)
+			sort.expr = distanceKm(*filter.Near)
 		}
 
-		if filter.Location != nil {
-			query = query.Joins("JOIN carbon_credits ON carbon_credits.id = credit_listings.carbon_credits_id").
-				Joins("JOIN lands ON lands.id = carbon_credits.land_id").
-				Where("lands.location = ?", *filter.Location)
+	case filter.Near != nil:
+		sort.key = fmt.Sprintf("distance:%g,%g", filter.Near.Lat, filter.Near.Lon)
+		sort.expr = distanceKm(*filter.Near)
+		sort.exprType = "double precision"
+
+	case filter.Query != nil:
+		sort.key = "-relevance:" + *filter.Query
+		sort.expr = clause.Expr{
+			SQL:  "ts_rank(lands.search_vector, websearch_to_tsquery('english', ?))",
+			Vars: []interface{}{*filter.Query},
 		}
-	}
+		sort.exprType = "real"
+		sort.desc = true
 
-	if err := query.Find(&listings).Error; err != nil {
-		return nil, err
+	default:
+		sort.key = "-createdAt"
+		sort.expr = clause.Expr{SQL: "credit_listings.created_at"}
+		sort.exprType = "timestamptz"
+		sort.desc = true
 	}
 
-	return listings, nil
+	return sort, nil
 }
 
 func (s *MarketSVC) GetActiveListingByID(ctx context.Context, id uuid.UUID) (*CreditListing, error) {
@@ -125,17 +186,44 @@ func (s *MarketSVC) CreateListing(ctx context.Context, userID uuid.UUID, req Cre
 		return nil, ErrUnauthorized
 	}
 
+	if req.Status == "" {
+		req.Status = "draft"
+	}
+	if req.Status != "draft" && req.Status != "active" {
+		return nil, ErrInvalidStatus
+	}
+	if req.Quantity <= 0 || req.MinimumPurchase > req.Quantity ||
+		!validListingTerms(req.PricePerCredit, req.MinimumPurchase, req.MaximumPurchase) {
+		return nil, ErrInvalidListing
+	}
+
 	listing := &CreditListing{
 		CarbonCreditsID: req.CarbonCreditsID,
 		PricePerCredit:  req.PricePerCredit,
 		MinimumPurchase: req.MinimumPurchase,
 		MaximumPurchase: req.MaximumPurchase,
 		Status:          req.Status,
+		Quantity:        req.Quantity,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
 
-	if err := s.db.WithContext(ctx).Create(listing).Error; err != nil {
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if listing.Status == "active" {
+			if err := checkListable(tx, req.CarbonCreditsID); err != nil {
+				return err
+			}
+		}
+		if err := checkCreditAvailability(tx, req.CarbonCreditsID, req.Quantity); err != nil {
+			return err
+		}
+		return tx.Create(listing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.matchActiveListing(ctx, listing); err != nil {
 		return nil, err
 	}
 
@@ -143,53 +231,1760 @@ func (s *MarketSVC) CreateListing(ctx context.Context, userID uuid.UUID, req Cre
 }
 
 func (s *MarketSVC) UpdateListing(ctx context.Context, userID, listingID uuid.UUID, req UpdateListingRequest) (*CreditListing, error) {
+	if !validListingTerms(req.PricePerCredit, req.MinimumPurchase, req.MaximumPurchase) {
+		return nil, ErrInvalidListing
+	}
+
+	var listing *CreditListing
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if listing, err = lockSellerListing(tx, userID, listingID); err != nil {
+			return err
+		}
+
+		// Sold and cancelled listings are final
+		if _, ok := listingTransitions[listing.Status]; !ok {
+			return ErrInvalidTransition
+		}
+
+		listing.PricePerCredit = req.PricePerCredit
+		listing.MinimumPurchase = req.MinimumPurchase
+		listing.MaximumPurchase = req.MaximumPurchase
+
+		if req.Quantity != nil && *req.Quantity != listing.Quantity {
+			if err := setListingQuantity(tx, listing, *req.Quantity); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		if req.Status != nil && *req.Status != listing.Status {
+			if err := setListingStatus(tx, listing, *req.Status, now); err != nil {
+				return err
+			}
+		}
+		listing.UpdatedAt = now
+
+		return tx.Save(listing).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.matchActiveListing(ctx, listing); err != nil {
+		return nil, err
+	}
+
+	return listing, nil
+}
+
+// PublishListing moves a draft listing to active, opening it to buyers and
+// to resting buy orders.
+func (s *MarketSVC) PublishListing(ctx context.Context, userID, listingID uuid.UUID) (*CreditListing, error) {
+	listing, err := s.transitionListing(ctx, userID, listingID, "active")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.matchActiveListing(ctx, listing); err != nil {
+		return nil, err
+	}
+
+	return listing, nil
+}
+
+// CancelListing withdraws a draft or active listing and returns its unsold
+// quantity to the batch. Held reservations on it are released, since they
+// could no longer be confirmed.
+func (s *MarketSVC) CancelListing(ctx context.Context, userID, listingID uuid.UUID) (*CreditListing, error) {
+	return s.transitionListing(ctx, userID, listingID, "cancelled")
+}
+
+func (s *MarketSVC) transitionListing(ctx context.Context, userID, listingID uuid.UUID, status string) (*CreditListing, error) {
+	var listing *CreditListing
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var err error
+		if listing, err = lockSellerListing(tx, userID, listingID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := setListingStatus(tx, listing, status, now); err != nil {
+			return err
+		}
+		listing.UpdatedAt = now
+
+		return tx.Model(listing).Updates(map[string]interface{}{
+			"status":     listing.Status,
+			"quantity":   listing.Quantity,
+			"updated_at": now,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return listing, nil
+}
+
+// validListingTerms reports whether a listing's price and purchase limits make
+// sense on their own; limits against the quantity are checked by the caller.
+func validListingTerms(pricePerCredit, minimumPurchase float64, maximumPurchase *float64) bool {
+	return pricePerCredit > 0 && minimumPurchase >= 0 &&
+		(maximumPurchase == nil || *maximumPurchase >= minimumPurchase)
+}
+
+// listingTransitions lists the status changes a seller may make to a listing.
+// A listing only becomes sold by selling out (and returns to active if a
+// refund puts credits back), and sold or cancelled listings cannot be
+// changed. Active listings go back to draft only through pullListing.
+var listingTransitions = map[string][]string{
+	"draft":  {"active", "cancelled"},
+	"active": {"cancelled"},
+}
+
+// setListingStatus validates a seller's status change and applies its side
+// effects; the caller persists the listing.
+func setListingStatus(tx *gorm.DB, listing *CreditListing, status string, now time.Time) error {
+	if !slices.Contains(listingTransitions[listing.Status], status) {
+		return ErrInvalidTransition
+	}
+
+	switch status {
+	case "active":
+		if listing.Quantity <= 0 {
+			return ErrInvalidListing
+		}
+		if err := checkListable(tx, listing.CarbonCreditsID); err != nil {
+			return err
+		}
+	case "cancelled":
+		if err := releaseListingHolds(tx, listing.ID, now); err != nil {
+			return err
+		}
+		listing.Quantity = 0
+	}
+
+	listing.Status = status
+	return nil
+}
+
+// lockSellerListing loads one of the seller's own listings for a status or
+// price change. The batch is locked before the listing, the same order every
+// sale takes, so the change serializes with concurrent purchases.
+func lockSellerListing(tx *gorm.DB, userID, listingID uuid.UUID) (*CreditListing, error) {
 	var listing CreditListing
-	err := s.db.WithContext(ctx).
-		Joins("JOIN carbon_credits ON credit_listings.carbon_credits_id = carbon_credits.id").
+	if err := tx.Joins("JOIN carbon_credits ON credit_listings.carbon_credits_id = carbon_credits.id").
 		Joins("JOIN lands ON carbon_credits.land_id = lands.id").
 		Joins("JOIN sellers ON lands.owner_id = sellers.id").
-		Where("credit_listings.id = ? AND sellers.user_id = ?", listingID, userID).
-		First(&listing).Error
-
-	if err != nil {
+		Where("credit_listings.id = ? AND sellers.user_id = ? AND credit_listings.wallet_id IS NULL", listingID, userID).
+		First(&listing).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, ErrNotFound
 		}
 		return nil, err
 	}
 
-	listing.PricePerCredit = req.PricePerCredit
-	listing.MinimumPurchase = req.MinimumPurchase
-	listing.MaximumPurchase = req.MaximumPurchase
-	listing.Status = req.Status
-	listing.UpdatedAt = time.Now()
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", listing.CarbonCreditsID).
+		First(&CarbonCredit{}).Error; err != nil {
+		return nil, err
+	}
 
-	if err := s.db.WithContext(ctx).Save(&listing).Error; err != nil {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", listing.ID).
+		First(&listing).Error; err != nil {
 		return nil, err
 	}
 
 	return &listing, nil
 }
 
+// setListingQuantity changes the credits allocated to a primary listing; the
+// caller persists the listing. Growing it takes unallocated credits from the
+// batch, and it cannot shrink below what buyers are holding at checkout.
+func setListingQuantity(tx *gorm.DB, listing *CreditListing, quantity float64) error {
+	if quantity <= 0 || listing.MinimumPurchase > quantity {
+		return ErrInvalidListing
+	}
+
+	if quantity > listing.Quantity {
+		if err := checkCreditAvailability(tx, listing.CarbonCreditsID, quantity-listing.Quantity); err != nil {
+			return err
+		}
+	}
+
+	held, err := heldCredits(tx, listing.ID)
+	if err != nil {
+		return err
+	}
+	if quantity < held {
+		return ErrInvalidListing
+	}
+
+	listing.Quantity = quantity
+	return nil
+}
+
+// CheckCreditAvailability reports whether amount more credits of one of the
+// seller's batches can be allocated to listings or auctions, returning
+// ErrInsufficientCredits if not.
+func (s *MarketSVC) CheckCreditAvailability(ctx context.Context, userID, creditID uuid.UUID, amount float64) error {
+	if amount <= 0 {
+		return ErrInvalidAmount
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&CarbonCredit{}).
+			Joins("JOIN lands ON carbon_credits.land_id = lands.id").
+			Joins("JOIN sellers ON lands.owner_id = sellers.id").
+			Where("carbon_credits.id = ? AND sellers.user_id = ?", creditID, userID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrCreditNotFound
+		}
+
+		return checkCreditAvailability(tx, creditID, amount)
+	})
+}
+
+// checkCreditAvailability locks the batch and checks that amount more of it
+// is left unallocated. The lock keeps the check valid until the caller's
+// transaction allocates the credits.
+func checkCreditAvailability(tx *gorm.DB, creditID uuid.UUID, amount float64) error {
+	var credit CarbonCredit
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", creditID).
+		First(&credit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCreditNotFound
+		}
+		return err
+	}
+
+	unallocated, err := unallocatedCredits(tx, &credit)
+	if err != nil {
+		return err
+	}
+	if amount > unallocated {
+		return ErrInsufficientCredits
+	}
+
+	return nil
+}
+
+// releaseListingHolds releases the held reservations on a listing that can
+// no longer be bought from.
+func releaseListingHolds(tx *gorm.DB, listingID uuid.UUID, now time.Time) error {
+	return tx.Model(&CreditReservation{}).
+		Where("listing_id = ? AND status = ?", listingID, "held").
+		Updates(map[string]interface{}{
+			"status":     "released",
+			"updated_at": now,
+		}).Error
+}
+
+// matchActiveListing runs the matching engine for a listing that was just
+// published or repriced and refreshes it, since fills may have sold it out.
+// A failed match leaves the listing untouched for the next trigger.
+func (s *MarketSVC) matchActiveListing(ctx context.Context, listing *CreditListing) error {
+	if listing.Status != "active" {
+		return nil
+	}
+
+	if err := s.matchListing(ctx, listing.ID); err != nil {
+		log.Printf("matching listing %s failed: %v", listing.ID, err)
+		return nil
+	}
+
+	return s.db.WithContext(ctx).First(listing, "id = ?", listing.ID).Error
+}
+
+// DeleteListing removes one of the seller's draft listings, which gives its
+// quantity back to the batch. Listings that were ever published keep their
+// record for the holds and purchases made through them and have to be
+// cancelled instead.
 func (s *MarketSVC) DeleteListing(ctx context.Context, userID, listingID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		listing, err := lockSellerListing(tx, userID, listingID)
+		if err != nil {
+			return err
+		}
+
+		if listing.Status != "draft" {
+			return ErrInvalidTransition
+		}
+
+		// Drafts pulled from the market may have been bought from
+		var used int64
+		if err := tx.Model(&Purchase{}).Where("listing_id = ?", listing.ID).Count(&used).Error; err != nil {
+			return err
+		}
+		if used == 0 {
+			if err := tx.Model(&CreditReservation{}).Where("listing_id = ?", listing.ID).Count(&used).Error; err != nil {
+				return err
+			}
+		}
+		if used > 0 {
+			return ErrInvalidTransition
+		}
+
+		return tx.Delete(listing).Error
+	})
+}
+
+func (s *MarketSVC) ReserveCredits(ctx context.Context, userID, listingID uuid.UUID, req ReserveCreditsRequest) (*CreditReservation, error) {
+	var reservation CreditReservation
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var listing CreditListing
+		if err := tx.Where("id = ? AND status = ?", listingID, "active").First(&listing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		if err := checkPurchaseLimits(&listing, req.Amount); err != nil {
+			return err
+		}
+
+		// Lock the credit batch so concurrent holds and orders are counted
+		// against each other
+		var credit CarbonCredit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", listing.CarbonCreditsID).
+			First(&credit).Error; err != nil {
+			return err
+		}
+
+		// The listing sweeper may not have pulled it yet
+		if err := checkListable(tx, listing.CarbonCreditsID); err != nil {
+			return err
+		}
+
+		available, err := purchasableCredits(tx, &listing)
+		if err != nil {
+			return err
+		}
+		if req.Amount > available {
+			return ErrInsufficientCredits
+		}
+
+		now := time.Now()
+		reservation = CreditReservation{
+			ListingID:       listing.ID,
+			CarbonCreditsID: credit.ID,
+			BuyerID:         userID,
+			Amount:          req.Amount,
+			PricePerCredit:  listing.PricePerCredit,
+			Status:          "held",
+			ExpiresAt:       now.Add(s.cfg.ReservationTTL),
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		return tx.Create(&reservation).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.attachPaymentIntent(ctx, &reservation); err != nil {
+		s.releaseHold(ctx, &reservation)
+		return nil, err
+	}
+
+	return &reservation, nil
+}
+
+// attachPaymentIntent opens the payment for a hold. It runs after the hold is
+// committed, so the gateway is never called with the batch locked.
+func (s *MarketSVC) attachPaymentIntent(ctx context.Context, reservation *CreditReservation) error {
+	intent, err := s.payments.CreateIntent(ctx, reservation.Amount*reservation.PricePerCredit, paymentCurrency, reservation.ID.String())
+	if err != nil {
+		return err
+	}
+
+	reservation.PaymentIntentID = &intent.ID
+	return s.db.WithContext(ctx).
+		Model(reservation).
+		Update("payment_intent_id", intent.ID).Error
+}
+
+// releaseHold gives back the credits of a hold that will not be paid for.
+// Failures are logged; the hold expires on its own anyway.
+func (s *MarketSVC) releaseHold(ctx context.Context, reservation *CreditReservation) {
+	if err := s.db.WithContext(ctx).
+		Model(&CreditReservation{}).
+		Where("id = ? AND status = ?", reservation.ID, "held").
+		Updates(map[string]interface{}{
+			"status":     "released",
+			"updated_at": time.Now(),
+		}).Error; err != nil {
+		log.Printf("releasing reservation %s failed: %v", reservation.ID, err)
+	}
+}
+
+func (s *MarketSVC) ConfirmReservation(ctx context.Context, userID, reservationID uuid.UUID) (*Purchase, error) {
+	var reservation CreditReservation
+	if err := s.db.WithContext(ctx).
+		Where("id = ? AND buyer_id = ? AND status = ?", reservationID, userID, "held").
+		First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	return s.payHold(ctx, &reservation)
+}
+
+// payHold charges the buyer for a hold and turns it into a purchase. The hold
+// keeps its credits from everyone else, so the payment is confirmed before
+// any lock is taken and a slow gateway holds up no other buyer of the batch.
+// A payment taken for a hold that can no longer be filled is refunded.
+func (s *MarketSVC) payHold(ctx context.Context, reservation *CreditReservation) (*Purchase, error) {
+	if !time.Now().Before(reservation.ExpiresAt) {
+		return nil, ErrReservationExpired
+	}
+
+	if reservation.PaymentIntentID == nil {
+		return nil, ErrPaymentFailed
+	}
+
+	intent, err := s.payments.ConfirmIntent(ctx, *reservation.PaymentIntentID)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != "succeeded" {
+		return nil, ErrPaymentFailed
+	}
+
+	var purchase *Purchase
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		credit, err := lockReservationBatch(tx, "id = ?", reservation.ID)
+		if err != nil {
+			return err
+		}
+
+		locked, err := lockHeldReservation(tx, reservation.BuyerID, reservation.ID)
+		if err != nil {
+			return err
+		}
+
+		purchase, err = completeReservation(tx, locked, credit)
+		return err
+	})
+
+	if err != nil {
+		// Don't keep the buyer's money for credits they didn't get
+		if rerr := s.refundCheckout(ctx, reservation); rerr != nil {
+			log.Printf("refunding reservation %s failed: %v", reservation.ID, rerr)
+		}
+		return nil, err
+	}
+
+	return purchase, nil
+}
+
+// refundCheckout refunds the payment of a hold that will not become a
+// purchase and releases the hold, so a later webhook can neither complete it
+// nor refund it again. The refund is claimed on the reservation first so it
+// is issued at most once; a failed refund gives the claim back for a retry.
+func (s *MarketSVC) refundCheckout(ctx context.Context, reservation *CreditReservation) error {
+	now := time.Now()
 	result := s.db.WithContext(ctx).
-		Joins("JOIN carbon_credits ON credit_listings.carbon_credits_id = carbon_credits.id").
-		Joins("JOIN lands ON carbon_credits.land_id = lands.id").
-		Joins("JOIN sellers ON lands.owner_id = sellers.id").
-		Where("credit_listings.id = ? AND sellers.user_id = ?", listingID, userID).
-		Delete(&CreditListing{})
+		Model(&CreditReservation{}).
+		Where("id = ? AND status <> ? AND refunded_at IS NULL", reservation.ID, "confirmed").
+		Updates(map[string]interface{}{
+			"status":      gorm.Expr("CASE WHEN status = 'held' THEN 'released' ELSE status END"),
+			"refunded_at": now,
+			"updated_at":  now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	if err := s.payments.Refund(ctx, *reservation.PaymentIntentID, reservation.Amount*reservation.PricePerCredit); err != nil {
+		if uerr := s.db.WithContext(ctx).
+			Model(&CreditReservation{}).
+			Where("id = ?", reservation.ID).
+			Update("refunded_at", nil).Error; uerr != nil {
+			log.Printf("releasing refund claim on reservation %s failed: %v", reservation.ID, uerr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// completeReservation turns a paid hold into a purchase at the reserved price.
+// The caller must hold a lock on the reservation's batch, taken before the
+// lock on the reservation itself. It checks everything before writing, so a
+// caller may still commit after ErrReservationExpired, ErrNotFound or
+// ErrInsufficientCredits.
+func completeReservation(tx *gorm.DB, reservation *CreditReservation, credit *CarbonCredit) (*Purchase, error) {
+	// An expired hold no longer keeps its credits from other buyers
+	if !time.Now().Before(reservation.ExpiresAt) {
+		return nil, ErrReservationExpired
+	}
+
+	var listing CreditListing
+	if err := tx.Where("id = ? AND status = ?", reservation.ListingID, "active").First(&listing).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	// The hold kept these credits out of everyone else's reach, so the
+	// listing only needs to still contain them
+	if reservation.Amount > listing.Quantity {
+		return nil, ErrInsufficientCredits
+	}
+
+	purchase := &Purchase{
+		BuyerID:         reservation.BuyerID,
+		BuyOrderID:      reservation.BuyOrderID,
+		Amount:          reservation.Amount,
+		PricePerCredit:  reservation.PricePerCredit,
+		TransactionHash: reservation.PaymentIntentID,
+	}
+	if err := fillListing(tx, &listing, credit, purchase); err != nil {
+		return nil, err
+	}
+
+	return purchase, tx.Model(reservation).Updates(map[string]interface{}{
+		"status":      "confirmed",
+		"purchase_id": purchase.ID,
+		"updated_at":  time.Now(),
+	}).Error
+}
+
+func (s *MarketSVC) ReleaseReservation(ctx context.Context, userID, reservationID uuid.UUID) error {
+	result := s.db.WithContext(ctx).
+		Model(&CreditReservation{}).
+		Where("id = ? AND buyer_id = ? AND status = ?", reservationID, userID, "held").
+		Updates(map[string]interface{}{
+			"status":     "released",
+			"updated_at": time.Now(),
+		})
 
 	if result.Error != nil {
 		return result.Error
 	}
 
 	if result.RowsAffected == 0 {
-		return ErrNotFound
+		return ErrReservationNotFound
 	}
 
 	return nil
 }
 
-// func (s *MarketSVC) CheckCreditAvailability(ctx context.Context, creditID uuid.UUID, amount float64) error {
-// 	return nil
-// }
+// HandlePaymentWebhook applies an asynchronous payment outcome reported by the
+// provider: a successful payment completes the matching checkout hold and a
+// failed one releases it. A payment for a hold that has expired, was released
+// or can no longer be filled is refunded. Events for anything else are
+// acknowledged and ignored, so redelivered webhooks are harmless.
+func (s *MarketSVC) HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error {
+	event, err := s.payments.HandleWebhook(ctx, payload, signature)
+	if err != nil {
+		return err
+	}
+
+	switch event.Type {
+	case "payment_succeeded":
+		var unfilled *CreditReservation
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			// Direct orders confirm their payment synchronously and have no hold
+			credit, err := lockReservationBatch(tx, "payment_intent_id = ?", event.IntentID)
+			if errors.Is(err, ErrReservationNotFound) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+
+			var reservation CreditReservation
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("payment_intent_id = ?", event.IntentID).
+				First(&reservation).Error; err != nil {
+				return err
+			}
+
+			if reservation.Status == "confirmed" || reservation.RefundedAt != nil {
+				return nil
+			}
+
+			if reservation.Status == "held" {
+				_, err := completeReservation(tx, &reservation, credit)
+				switch err {
+				case ErrReservationExpired, ErrNotFound, ErrInsufficientCredits:
+				default:
+					return err
+				}
+			}
+
+			unfilled = &reservation
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		if unfilled != nil {
+			return s.refundCheckout(ctx, unfilled)
+		}
+		return nil
+
+	case "payment_failed":
+		return s.db.WithContext(ctx).
+			Model(&CreditReservation{}).
+			Where("payment_intent_id = ? AND status = ?", event.IntentID, "held").
+			Updates(map[string]interface{}{
+				"status":     "released",
+				"updated_at": time.Now(),
+			}).Error
+	}
+
+	return nil
+}
+
+// ReleaseExpiredReservations marks holds past their expiry as expired. Expired
+// holds already stop counting against availability, so this only keeps the
+// table tidy and is safe to run from several instances at once.
+func (s *MarketSVC) ReleaseExpiredReservations(ctx context.Context) (int64, error) {
+	now := time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&CreditReservation{}).
+		Where("status = ? AND expires_at <= ?", "held", now).
+		Updates(map[string]interface{}{
+			"status":     "expired",
+			"updated_at": now,
+		})
+
+	return result.RowsAffected, result.Error
+}
+
+// lockReservationBatch locks the batch of the reservation matching query.
+// Checkouts take the batch lock before the reservation lock, the same order
+// sales and listing changes take before releasing holds, so they cannot
+// deadlock with each other.
+func lockReservationBatch(tx *gorm.DB, query interface{}, args ...interface{}) (*CarbonCredit, error) {
+	var reservation CreditReservation
+	if err := tx.Where(query, args...).First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+
+	var credit CarbonCredit
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", reservation.CarbonCreditsID).
+		First(&credit).Error; err != nil {
+		return nil, err
+	}
+
+	return &credit, nil
+}
+
+func lockHeldReservation(tx *gorm.DB, userID, reservationID uuid.UUID) (*CreditReservation, error) {
+	var reservation CreditReservation
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND buyer_id = ? AND status = ?", reservationID, userID, "held").
+		First(&reservation).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReservationNotFound
+		}
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+// unallocatedCredits returns how many of the batch's available credits are
+// neither allocated to an open primary listing nor set aside for an open
+// auction. Checkout holds are always taken on a listing, so they come out of
+// its allocation rather than the batch.
+func unallocatedCredits(tx *gorm.DB, credit *CarbonCredit) (float64, error) {
+	var listed, auctioned float64
+	if err := tx.Model(&CreditListing{}).
+		Where("carbon_credits_id = ? AND wallet_id IS NULL AND status IN ?", credit.ID, []string{"draft", "active"}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&listed).Error; err != nil {
+		return 0, err
+	}
+	if err := tx.Model(&CreditAuction{}).
+		Where("carbon_credits_id = ? AND status IN ?", credit.ID, []string{"pending", "active"}).
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&auctioned).Error; err != nil {
+		return 0, err
+	}
+	return credit.CreditsAvailable - listed - auctioned, nil
+}
+
+// heldCredits returns how much of a listing live checkout holds keep back.
+func heldCredits(tx *gorm.DB, listingID uuid.UUID) (float64, error) {
+	var held float64
+	err := tx.Model(&CreditReservation{}).
+		Where("listing_id = ? AND status = ? AND expires_at > ?", listingID, "held", time.Now()).
+		Select("COALESCE(SUM(amount), 0)").
+		Scan(&held).Error
+	return held, err
+}
+
+// purchasableCredits returns how much can still be bought through a listing:
+// the unheld part of its quantity. The caller must hold a lock on the
+// listing's batch, which serialises every sale from it, so the listing is
+// reloaded here to see the effect of earlier ones.
+func purchasableCredits(tx *gorm.DB, listing *CreditListing) (float64, error) {
+	if err := tx.First(listing, "id = ?", listing.ID).Error; err != nil {
+		return 0, err
+	}
+
+	held, err := heldCredits(tx, listing.ID)
+	if err != nil {
+		return 0, err
+	}
+	return listing.Quantity - held, nil
+}
+
+// PlaceOrder buys credits from a listing in one step: the credits are held
+// like a checkout, the buyer is charged with no lock held, and the hold
+// becomes the purchase.
+func (s *MarketSVC) PlaceOrder(ctx context.Context, userID, listingID uuid.UUID, req PlaceOrderRequest) (*Purchase, error) {
+	reservation, err := s.ReserveCredits(ctx, userID, listingID, ReserveCreditsRequest{Amount: req.Amount})
+	if err != nil {
+		return nil, err
+	}
+
+	purchase, err := s.payHold(ctx, reservation)
+	if err != nil {
+		s.releaseHold(ctx, reservation)
+		return nil, err
+	}
+
+	return purchase, nil
+}
+
+// RefundPurchase reverses a purchase within the grace period: the credits go
+// back where they were bought from, the wallet entry is emptied, and the
+// payment is refunded once that has been committed, so a gateway refund is
+// never issued for a reversal that rolled back. A failed gateway refund
+// leaves the purchase pending for RetryPaymentRefunds.
+func (s *MarketSVC) RefundPurchase(ctx context.Context, userID, purchaseID uuid.UUID) (*Purchase, error) {
+	var purchase Purchase
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND buyer_id = ? AND refunded_at IS NULL", purchaseID, userID).
+			First(&purchase).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrPurchaseNotFound
+			}
+			return err
+		}
+
+		now := time.Now()
+		if now.Sub(purchase.PurchaseDate) > s.cfg.RefundGracePeriod {
+			return ErrRefundWindowClosed
+		}
+
+		// Lock the batch before the wallet entry and listing, like sales do
+		var credit CarbonCredit
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", purchase.CarbonCreditsID).
+			First(&credit).Error; err != nil {
+			return err
+		}
+
+		// Every credit bought must still sit untouched in the buyer's own
+		// wallet entry; anything retired or transferred is already spent.
+		// Credits transferred away and back land in another entry of the
+		// same purchase, so any transfer rules the refund out.
+		var transfers int64
+		if err := tx.Model(&CreditTransfer{}).
+			Where("purchase_id = ?", purchase.ID).
+			Count(&transfers).Error; err != nil {
+			return err
+		}
+		if transfers > 0 {
+			return ErrCreditsInUse
+		}
+
+		var wallet CreditWallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("purchase_id = ? AND owner_id = ?", purchase.ID, userID).
+			First(&wallet).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrCreditsInUse
+			}
+			return err
+		}
+		if wallet.CreditsRemaining < purchase.Amount {
+			return ErrCreditsInUse
+		}
+
+		// Emptied rather than deleted: resale listings may still point at it
+		if err := tx.Model(&wallet).Updates(map[string]interface{}{
+			"credits_remaining": 0,
+			"updated_at":        now,
+		}).Error; err != nil {
+			return err
+		}
+
+		if err := restorePurchasedCredits(tx, &purchase, &credit, now); err != nil {
+			return err
+		}
+
+		purchase.RefundedAt = &now
+		return tx.Model(&purchase).Update("refunded_at", now).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.refundPurchasePayment(ctx, &purchase); err != nil {
+		log.Printf("refunding payment of purchase %s failed, will retry: %v", purchase.ID, err)
+	}
+
+	return &purchase, nil
+}
+
+// RetryPaymentRefunds retries the gateway refunds of reversed purchases whose
+// payment has not been returned yet. Failures are logged and left for the
+// next run; it is safe to run from several instances at once.
+func (s *MarketSVC) RetryPaymentRefunds(ctx context.Context) (int64, error) {
+	var pending []Purchase
+	if err := s.db.WithContext(ctx).
+		Where("refunded_at IS NOT NULL AND payment_refunded_at IS NULL AND transaction_hash IS NOT NULL").
+		Order("refunded_at ASC").
+		Find(&pending).Error; err != nil {
+		return 0, err
+	}
+
+	var refunded int64
+	for i := range pending {
+		if err := s.refundPurchasePayment(ctx, &pending[i]); err != nil {
+			log.Printf("refunding payment of purchase %s failed: %v", pending[i].ID, err)
+			continue
+		}
+		if pending[i].PaymentRefundedAt != nil {
+			refunded++
+		}
+	}
+
+	return refunded, nil
+}
+
+// refundPurchasePayment returns the payment of a reversed purchase. Like
+// refundCheckout it claims the refund first so it is issued at most once, and
+// a failed refund gives the claim back for a retry.
+func (s *MarketSVC) refundPurchasePayment(ctx context.Context, purchase *Purchase) error {
+	if purchase.TransactionHash == nil {
+		return nil
+	}
+
+	now := time.Now()
+	result := s.db.WithContext(ctx).
+		Model(&Purchase{}).
+		Where("id = ? AND refunded_at IS NOT NULL AND payment_refunded_at IS NULL", purchase.ID).
+		Update("payment_refunded_at", now)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	if err := s.payments.Refund(ctx, *purchase.TransactionHash, purchase.TotalPrice); err != nil {
+		if uerr := s.db.WithContext(ctx).
+			Model(&Purchase{}).
+			Where("id = ?", purchase.ID).
+			Update("payment_refunded_at", nil).Error; uerr != nil {
+			log.Printf("releasing refund claim on purchase %s failed: %v", purchase.ID, uerr)
+		}
+		return err
+	}
+
+	purchase.PaymentRefundedAt = &now
+	return nil
+}
+
+// GetWalletHoldings groups the buyer's wallet entries by vintage, biome and
+// land. Only the buyer's own purchases count as purchased and feed the
+// average price, weighted by amount; entries received by transfer share
+// another buyer's purchase and only add to the remaining and retired totals.
+func (s *MarketSVC) GetWalletHoldings(ctx context.Context, userID uuid.UUID) ([]WalletHolding, error) {
+	var holdings []WalletHolding
+
+	retired := s.db.Model(&CreditRetirement{}).
+		Select("wallet_id, SUM(quantity) AS quantity").
+		Group("wallet_id")
+
+	if err := s.db.WithContext(ctx).
+		Table("credit_wallets").
+		Select(`carbon_credits.vintage_year,
+			lands.biome_type,
+			lands.id AS land_id,
+			lands.title AS land_title,
+			SUM(CASE WHEN purchases.buyer_id = credit_wallets.owner_id THEN purchases.amount ELSE 0 END) AS purchased,
+			SUM(credit_wallets.credits_remaining) AS remaining,
+			COALESCE(SUM(retired.quantity), 0) AS retired,
+			COALESCE(SUM(CASE WHEN purchases.buyer_id = credit_wallets.owner_id THEN purchases.price_per_credit * purchases.amount END) /
+				NULLIF(SUM(CASE WHEN purchases.buyer_id = credit_wallets.owner_id THEN purchases.amount END), 0), 0) AS average_price_per_credit`).
+		Joins("JOIN purchases ON purchases.id = credit_wallets.purchase_id").
+		Joins("JOIN carbon_credits ON carbon_credits.id = purchases.carbon_credits_id").
+		Joins("JOIN lands ON lands.id = carbon_credits.land_id").
+		Joins("LEFT JOIN (?) AS retired ON retired.wallet_id = credit_wallets.id", retired).
+		Where("credit_wallets.owner_id = ? AND purchases.refunded_at IS NULL", userID).
+		Group("carbon_credits.vintage_year, lands.biome_type, lands.id, lands.title").
+		Order("carbon_credits.vintage_year DESC NULLS LAST, lands.title ASC").
+		Scan(&holdings).Error; err != nil {
+		return nil, err
+	}
+
+	return holdings, nil
+}
+
+// restorePurchasedCredits puts refunded credits back where they were bought
+// from. Primary sales go back into the batch and, like resale purchases, onto
+// their listing; a sold listing becomes active again, or draft when its
+// credits may no longer be listed. When that listing has since been
+// cancelled, primary credits stay unallocated in the batch and resale credits
+// go straight to the reseller's wallet entry. The caller must hold a lock on
+// credit, the purchase's batch.
+func restorePurchasedCredits(tx *gorm.DB, purchase *Purchase, credit *CarbonCredit, now time.Time) error {
+	var listing CreditListing
+	if purchase.ListingID != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", *purchase.ListingID).
+			First(&listing).Error; err != nil {
+			return err
+		}
+	}
+
+	if listing.WalletID == nil {
+		if err := tx.Model(credit).
+			Updates(map[string]interface{}{
+				"credits_available": gorm.Expr("credits_available + ?", purchase.Amount),
+				"credits_sold":      gorm.Expr("credits_sold - ?", purchase.Amount),
+			}).Error; err != nil {
+			return err
+		}
+	}
+
+	// Auction purchases have no listing to go back to
+	if purchase.ListingID == nil {
+		return nil
+	}
+
+	if listing.Status == "cancelled" {
+		if listing.WalletID == nil {
+			return nil
+		}
+		return tx.Model(&CreditWallet{}).
+			Where("id = ?", *listing.WalletID).
+			Updates(map[string]interface{}{
+				"credits_remaining": gorm.Expr("credits_remaining + ?", purchase.Amount),
+				"updated_at":        now,
+			}).Error
+	}
+
+	updates := map[string]interface{}{
+		"quantity":   gorm.Expr("quantity + ?", purchase.Amount),
+		"updated_at": now,
+	}
+	if listing.Status == "sold" {
+		updates["status"] = "active"
+
+		err := checkListable(tx, listing.CarbonCreditsID)
+		if isUnlistable(err) {
+			updates["status"] = "draft"
+		} else if err != nil {
+			return err
+		}
+	}
+	return tx.Model(&listing).Updates(updates).Error
+}
+
+// TransferCredits moves credits from one of the user's wallet entries to a
+// new entry owned by another buyer, without a sale. Only credits_remaining can
+// move; retired credits and credits reserved by a resale listing have already
+// been taken out of it.
+func (s *MarketSVC) TransferCredits(ctx context.Context, userID, walletID uuid.UUID, req TransferCreditsRequest) (*CreditTransfer, error) {
+	if req.Quantity <= 0 || req.RecipientID == uuid.Nil || req.RecipientID == userID {
+		return nil, ErrInvalidTransfer
+	}
+
+	var transfer CreditTransfer
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Table("buyers").Where("user_id = ?", req.RecipientID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrRecipientNotFound
+		}
+
+		var wallet CreditWallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND owner_id = ?", walletID, userID).
+			First(&wallet).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+
+		if req.Quantity > wallet.CreditsRemaining {
+			return ErrInsufficientCredits
+		}
+
+		now := time.Now()
+		if err := tx.Model(&wallet).Updates(map[string]interface{}{
+			"credits_remaining": gorm.Expr("credits_remaining - ?", req.Quantity),
+			"updated_at":        now,
+		}).Error; err != nil {
+			return err
+		}
+
+		received := CreditWallet{
+			OwnerID:          req.RecipientID,
+			PurchaseID:       wallet.PurchaseID,
+			CreditsRemaining: req.Quantity,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		if err := tx.Create(&received).Error; err != nil {
+			return err
+		}
+
+		transfer = CreditTransfer{
+			PurchaseID:    wallet.PurchaseID,
+			FromWalletID:  wallet.ID,
+			ToWalletID:    received.ID,
+			FromOwnerID:   userID,
+			ToOwnerID:     req.RecipientID,
+			Quantity:      req.Quantity,
+			TransferredAt: now,
+		}
+		return tx.Create(&transfer).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &transfer, nil
+}
+
+func (s *MarketSVC) RetireCredits(ctx context.Context, userID, walletID uuid.UUID, req RetireCreditsRequest) (*CreditRetirement, error) {
+	if req.Quantity <= 0 || strings.TrimSpace(req.BeneficiaryName) == "" {
+		return nil, ErrInvalidRetirement
+	}
+
+	var retirement CreditRetirement
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wallet CreditWallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Purchase").
+			Preload("Purchase.CarbonCredit").
+			Where("id = ? AND owner_id = ?", walletID, userID).
+			First(&wallet).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+
+		if req.Quantity > wallet.CreditsRemaining {
+			return ErrInsufficientCredits
+		}
+
+		now := time.Now()
+		if err := tx.Model(&wallet).Updates(map[string]interface{}{
+			"credits_remaining": gorm.Expr("credits_remaining - ?", req.Quantity),
+			"updated_at":        now,
+		}).Error; err != nil {
+			return err
+		}
+
+		retirement = CreditRetirement{
+			CertificateID:   newCertificateID(now),
+			WalletID:        wallet.ID,
+			OwnerID:         userID,
+			PurchaseID:      wallet.PurchaseID,
+			CarbonCreditsID: wallet.Purchase.CarbonCreditsID,
+			VintageYear:     wallet.Purchase.CarbonCredit.VintageYear,
+			Quantity:        req.Quantity,
+			BeneficiaryName: strings.TrimSpace(req.BeneficiaryName),
+			Reason:          req.Reason,
+			RetiredAt:       now,
+		}
+
+		return tx.Create(&retirement).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &retirement, nil
+}
+
+func (s *MarketSVC) GetRetirement(ctx context.Context, userID, retirementID uuid.UUID) (*CreditRetirement, error) {
+	var retirement CreditRetirement
+
+	if err := s.db.WithContext(ctx).
+		Preload("CarbonCredit").
+		Preload("CarbonCredit.Land").
+		Where("id = ? AND owner_id = ?", retirementID, userID).
+		First(&retirement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRetirementNotFound
+		}
+		return nil, err
+	}
+
+	return &retirement, nil
+}
+
+func (s *MarketSVC) VerifyCertificate(ctx context.Context, certificateID string) (*CertificateVerification, error) {
+	var retirement CreditRetirement
+
+	if err := s.db.WithContext(ctx).
+		Preload("CarbonCredit").
+		Preload("CarbonCredit.Land").
+		Where("certificate_id = ?", strings.ToUpper(certificateID)).
+		First(&retirement).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRetirementNotFound
+		}
+		return nil, err
+	}
+
+	return &CertificateVerification{
+		CertificateID:        retirement.CertificateID,
+		Valid:                true,
+		BeneficiaryName:      retirement.BeneficiaryName,
+		Quantity:             retirement.Quantity,
+		VintageYear:          retirement.VintageYear,
+		VerificationStandard: retirement.CarbonCredit.VerificationStandard,
+		LandTitle:            retirement.CarbonCredit.Land.Title,
+		Location:             retirement.CarbonCredit.Land.Location,
+		RetiredAt:            retirement.RetiredAt,
+	}, nil
+}
+
+// CreateResaleListing lists credits from one of the buyer's wallet entries
+// on the secondary market. The listed quantity is moved out of
+// credits_remaining into the listing until it sells or is cancelled.
+func (s *MarketSVC) CreateResaleListing(ctx context.Context, userID, walletID uuid.UUID, req CreateResaleListingRequest) (*CreditListing, error) {
+	if req.Quantity <= 0 || req.PricePerCredit <= 0 || req.MinimumPurchase < 0 ||
+		req.MinimumPurchase > req.Quantity ||
+		(req.MaximumPurchase != nil && *req.MaximumPurchase < req.MinimumPurchase) {
+		return nil, ErrInvalidListing
+	}
+
+	var listing CreditListing
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var wallet CreditWallet
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Preload("Purchase").
+			Where("id = ? AND owner_id = ?", walletID, userID).
+			First(&wallet).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrWalletNotFound
+			}
+			return err
+		}
+
+		if req.Quantity > wallet.CreditsRemaining {
+			return ErrInsufficientCredits
+		}
+
+		if err := checkListable(tx, wallet.Purchase.CarbonCreditsID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&wallet).Updates(map[string]interface{}{
+			"credits_remaining": gorm.Expr("credits_remaining - ?", req.Quantity),
+			"updated_at":        now,
+		}).Error; err != nil {
+			return err
+		}
+
+		listing = CreditListing{
+			CarbonCreditsID: wallet.Purchase.CarbonCreditsID,
+			PricePerCredit:  req.PricePerCredit,
+			MinimumPurchase: req.MinimumPurchase,
+			MaximumPurchase: req.MaximumPurchase,
+			Status:          "active",
+			WalletID:        &wallet.ID,
+			Quantity:        req.Quantity,
+			CreatedAt:       now,
+			UpdatedAt:       now,
+		}
+		return tx.Create(&listing).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.matchActiveListing(ctx, &listing); err != nil {
+		return nil, err
+	}
+
+	return &listing, nil
+}
+
+// CancelResaleListing withdraws a resale listing and returns its unsold
+// quantity to the wallet entry it came from. The batch is locked first, as
+// on every sale, so a concurrent purchase either completes before the
+// quantity is returned or finds the listing cancelled.
+func (s *MarketSVC) CancelResaleListing(ctx context.Context, userID, listingID uuid.UUID) error {
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var listing CreditListing
+		if err := tx.Joins("JOIN credit_wallets ON credit_wallets.id = credit_listings.wallet_id").
+			Where("credit_listings.id = ? AND credit_wallets.owner_id = ?", listingID, userID).
+			Where("credit_listings.status IN ?", []string{"draft", "active"}).
+			First(&listing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", listing.CarbonCreditsID).
+			First(&CarbonCredit{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND status IN ?", listing.ID, []string{"draft", "active"}).
+			First(&listing).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrNotFound
+			}
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&CreditWallet{}).
+			Where("id = ?", *listing.WalletID).
+			Updates(map[string]interface{}{
+				"credits_remaining": gorm.Expr("credits_remaining + ?", listing.Quantity),
+				"updated_at":        now,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := releaseListingHolds(tx, listing.ID, now); err != nil {
+			return err
+		}
+
+		return tx.Model(&listing).Updates(map[string]interface{}{
+			"status":     "cancelled",
+			"quantity":   0,
+			"updated_at": now,
+		}).Error
+	})
+}
+
+// newCertificateID returns a short, human-typeable identifier such as
+// GS-2026-3F9A1C0B72DE.
+func newCertificateID(now time.Time) string {
+	id := strings.ToUpper(strings.ReplaceAll(uuid.NewString(), "-", ""))
+	return fmt.Sprintf("GS-%d-%s", now.Year(), id[:12])
+}
+
+func checkPurchaseLimits(listing *CreditListing, amount float64) error {
+	if amount <= 0 || amount < listing.MinimumPurchase {
+		return ErrInvalidAmount
+	}
+	if listing.MaximumPurchase != nil && amount > *listing.MaximumPurchase {
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// fillListing sells purchase.Amount credits from a listing to
+// purchase.BuyerID at purchase.PricePerCredit: it takes the credits off the
+// listing's quantity, and out of the batch unless they come from a resale
+// listing, records the purchase and the buyer's wallet entry, and marks the
+// listing sold once its quantity reaches zero. The caller must hold a lock on
+// credit.
+func fillListing(tx *gorm.DB, listing *CreditListing, credit *CarbonCredit, purchase *Purchase) error {
+	now := time.Now()
+
+	if listing.WalletID == nil {
+		if err := tx.Model(credit).Updates(map[string]interface{}{
+			"credits_available": gorm.Expr("credits_available - ?", purchase.Amount),
+			"credits_sold":      gorm.Expr("credits_sold + ?", purchase.Amount),
+		}).Error; err != nil {
+			return err
+		}
+		credit.CreditsAvailable -= purchase.Amount
+		credit.CreditsSold += purchase.Amount
+	}
+
+	if err := tx.Model(listing).Updates(map[string]interface{}{
+		"quantity":   gorm.Expr("quantity - ?", purchase.Amount),
+		"updated_at": now,
+	}).Error; err != nil {
+		return err
+	}
+	listing.Quantity -= purchase.Amount
+
+	purchase.CarbonCreditsID = credit.ID
+	purchase.ListingID = &listing.ID
+	purchase.TotalPrice = purchase.Amount * purchase.PricePerCredit
+	purchase.PurchaseDate = now
+	if err := tx.Create(purchase).Error; err != nil {
+		return err
+	}
+
+	wallet := CreditWallet{
+		OwnerID:          purchase.BuyerID,
+		PurchaseID:       purchase.ID,
+		CreditsRemaining: purchase.Amount,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := tx.Create(&wallet).Error; err != nil {
+		return err
+	}
+
+	if listing.Quantity <= 0 {
+		listing.Status = "sold"
+		return tx.Model(listing).Updates(map[string]interface{}{
+			"status":     listing.Status,
+			"updated_at": now,
+		}).Error
+	}
+
+	return nil
+}
+
+func (s *MarketSVC) GetActiveAuctions(ctx context.Context, filter *FilterOptions, page, limit int) ([]CreditAuction, error) {
+	var auctions []CreditAuction
+	now := time.Now()
+
+	query := newAuctionQuery(s.db.WithContext(ctx).Model(&CreditAuction{})).apply(filter).db.
+		Preload("CarbonCredit").
+		Preload("CarbonCredit.Land").
+		Where("credit_auctions.status IN ? AND credit_auctions.start_time <= ? AND credit_auctions.end_time > ?",
+			[]string{"pending", "active"}, now, now).
+		Order("credit_auctions.end_time ASC, credit_auctions.id").
+		Offset((page - 1) * limit).
+		Limit(limit)
+
+	if err := query.Find(&auctions).Error; err != nil {
+		return nil, err
+	}
+
+	for i := range auctions {
+		auctions[i].setCurrentPrice(now)
+	}
+
+	return auctions, nil
+}
+
+func (s *MarketSVC) GetAuctionByID(ctx context.Context, id uuid.UUID) (*CreditAuction, error) {
+	var auction CreditAuction
+
+	if err := s.db.WithContext(ctx).
+		Preload("CarbonCredit").
+		Preload("CarbonCredit.Land").
+		Preload("Bids", func(db *gorm.DB) *gorm.DB {
+			return db.Order("auction_bids.bid_amount DESC")
+		}).
+		Where("credit_auctions.id = ?", id).
+		First(&auction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuctionNotFound
+		}
+		return nil, err
+	}
+
+	// Sealed bids stay hidden until the auction has been settled
+	if auction.AuctionType == "sealed_second_price" && (auction.Status == "pending" || auction.Status == "active") {
+		auction.Bids = nil
+	}
+	auction.setCurrentPrice(time.Now())
+
+	return &auction, nil
+}
+
+func (s *MarketSVC) CreateAuction(ctx context.Context, userID uuid.UUID, req CreateAuctionRequest) (*CreditAuction, error) {
+	if req.Quantity <= 0 || req.StartingPrice <= 0 || req.MinIncrement <= 0 ||
+		!req.EndTime.After(req.StartTime) || !req.EndTime.After(time.Now()) {
+		return nil, ErrInvalidAuction
+	}
+	if req.AuctionType == "" {
+		req.AuctionType = "english"
+	}
+
+	switch req.AuctionType {
+	case "english", "sealed_second_price":
+		if req.ReservePrice != nil && *req.ReservePrice < req.StartingPrice {
+			return nil, ErrInvalidAuction
+		}
+	case "dutch":
+		// The price falls from starting_price towards reserve_price, so the
+		// reserve is the floor and has to sit below the start
+		if req.ReservePrice == nil || *req.ReservePrice >= req.StartingPrice ||
+			req.PriceDropIntervalSeconds == nil || *req.PriceDropIntervalSeconds <= 0 {
+			return nil, ErrInvalidAuction
+		}
+	default:
+		return nil, ErrInvalidAuction
+	}
+
+	var count int64
+	err := s.db.WithContext(ctx).
+		Table("carbon_credits").
+		Joins("JOIN lands ON carbon_credits.land_id = lands.id").
+		Joins("JOIN sellers ON lands.owner_id = sellers.id").
+		Where("carbon_credits.id = ? AND sellers.user_id = ?", req.CarbonCreditsID, userID).
+		Count(&count).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		return nil, ErrUnauthorized
+	}
+
+	now := time.Now()
+	status := "pending"
+	if !req.StartTime.After(now) {
+		status = "active"
+	}
+
+	auction := &CreditAuction{
+		CarbonCreditsID:          req.CarbonCreditsID,
+		AuctionType:              req.AuctionType,
+		StartingPrice:            req.StartingPrice,
+		ReservePrice:             req.ReservePrice,
+		MinIncrement:             req.MinIncrement,
+		PriceDropIntervalSeconds: req.PriceDropIntervalSeconds,
+		Quantity:                 req.Quantity,
+		StartTime:                req.StartTime,
+		EndTime:                  req.EndTime,
+		Status:                   status,
+		CreatedAt:                now,
+		UpdatedAt:                now,
+	}
+
+	// The lot is set aside like a listing's quantity, so listings and other
+	// auctions cannot sell it while bidding runs
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := checkListable(tx, req.CarbonCreditsID); err != nil {
+			return err
+		}
+		if err := checkCreditAvailability(tx, req.CarbonCreditsID, req.Quantity); err != nil {
+			return err
+		}
+		return tx.Create(auction).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return auction, nil
+}
+
+func (s *MarketSVC) PlaceBid(ctx context.Context, userID, auctionID uuid.UUID, amount float64) (*AuctionBid, error) {
+	var bid *AuctionBid
+	var charged *PaymentIntent
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		auction, err := lockOpenAuction(tx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		switch auction.AuctionType {
+		case "dutch":
+			// The first buyer to accept the current price takes the lot
+			price := dutchPrice(auction, time.Now())
+			if amount < price {
+				return ErrBidTooLow
+			}
+			if bid, err = s.recordBid(tx, auction, userID, price, false); err != nil {
+				return err
+			}
+			charged, err = awardAuction(ctx, tx, s.payments, auction, userID, price)
+			return err
+
+		case "sealed_second_price":
+			if amount < auction.StartingPrice {
+				return ErrBidTooLow
+			}
+			bid, err = s.recordBid(tx, auction, userID, amount, false)
+			return err
+		}
+
+		highest, err := highestBid(tx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		if amount < nextMinimumBid(auction, highest) {
+			return ErrBidTooLow
+		}
+
+		if bid, err = s.recordBid(tx, auction, userID, amount, false); err != nil {
+			return err
+		}
+
+		return s.resolveProxyBids(tx, auction)
+	})
+
+	if err != nil {
+		if charged != nil {
+			refundCharges(ctx, s.payments, []*PaymentIntent{charged})
+		}
+		return nil, err
+	}
+
+	return bid, nil
+}
+
+func (s *MarketSVC) SetProxyBid(ctx context.Context, userID, auctionID uuid.UUID, maxAmount float64) (*AuctionProxyBid, error) {
+	var proxy AuctionProxyBid
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		auction, err := lockOpenAuction(tx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		if auction.AuctionType != "english" {
+			return ErrUnsupportedBid
+		}
+
+		highest, err := highestBid(tx, auctionID)
+		if err != nil {
+			return err
+		}
+
+		// A leader may raise their cap above their own bid; anyone else must
+		// be able to outbid the current leader at least once.
+		leading := highest != nil && highest.BidderID == userID
+		if (leading && maxAmount < highest.BidAmount) || (!leading && maxAmount < nextMinimumBid(auction, highest)) {
+			return ErrBidTooLow
+		}
+
+		now := time.Now()
+		proxy = AuctionProxyBid{
+			AuctionID: auctionID,
+			BidderID:  userID,
+			MaxAmount: maxAmount,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "auction_id"}, {Name: "bidder_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"max_amount", "updated_at"}),
+		}).Create(&proxy).Error; err != nil {
+			return err
+		}
+
+		return s.resolveProxyBids(tx, auction)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &proxy, nil
+}
+
+// lockOpenAuction locks the auction row so concurrent bids are validated
+// against each other, and checks that it is currently accepting bids.
+func lockOpenAuction(tx *gorm.DB, auctionID uuid.UUID) (*CreditAuction, error) {
+	var auction CreditAuction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", auctionID).
+		First(&auction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAuctionNotFound
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if auction.Status != "pending" && auction.Status != "active" {
+		return nil, ErrAuctionClosed
+	}
+	if now.Before(auction.StartTime) || !now.Before(auction.EndTime) {
+		return nil, ErrAuctionClosed
+	}
+
+	if auction.Status == "pending" {
+		auction.Status = "active"
+		if err := tx.Model(&auction).Updates(map[string]interface{}{
+			"status":     auction.Status,
+			"updated_at": now,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return &auction, nil
+}
+
+// highestBid returns the winning bid so far, or nil when there are no bids.
+// Ties go to the earliest bid.
+func highestBid(tx *gorm.DB, auctionID uuid.UUID) (*AuctionBid, error) {
+	var bid AuctionBid
+	err := tx.Where("auction_id = ?", auctionID).
+		Order("bid_amount DESC, bid_time ASC").
+		First(&bid).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &bid, nil
+}
+
+func nextMinimumBid(auction *CreditAuction, highest *AuctionBid) float64 {
+	if highest == nil {
+		return auction.StartingPrice
+	}
+	return highest.BidAmount + auction.MinIncrement
+}
+
+// dutchPrice drops the starting price by min_increment every price drop
+// interval since start_time, never going below the reserve price.
+func dutchPrice(auction *CreditAuction, now time.Time) float64 {
+	if now.Before(auction.StartTime) || auction.PriceDropIntervalSeconds == nil || *auction.PriceDropIntervalSeconds <= 0 {
+		return auction.StartingPrice
+	}
+
+	interval := time.Duration(*auction.PriceDropIntervalSeconds) * time.Second
+	steps := float64(now.Sub(auction.StartTime) / interval)
+	price := auction.StartingPrice - steps*auction.MinIncrement
+
+	if auction.ReservePrice != nil && price < *auction.ReservePrice {
+		return *auction.ReservePrice
+	}
+	return price
+}
+
+func (a *CreditAuction) setCurrentPrice(now time.Time) {
+	if a.AuctionType == "dutch" {
+		price := dutchPrice(a, now)
+		a.CurrentPrice = &price
+	}
+}
+
+// recordBid stores a bid and, for English auctions, pushes the end_time out
+// when the bid lands inside the anti-sniping window.
+func (s *MarketSVC) recordBid(tx *gorm.DB, auction *CreditAuction, bidderID uuid.UUID, amount float64, automatic bool) (*AuctionBid, error) {
+	now := time.Now()
+	bid := &AuctionBid{
+		AuctionID: auction.ID,
+		BidderID:  bidderID,
+		BidAmount: amount,
+		BidTime:   now,
+		Automatic: automatic,
+	}
+	if err := tx.Create(bid).Error; err != nil {
+		return nil, err
+	}
+
+	if auction.AuctionType == "english" && s.cfg.AntiSnipeWindow > 0 && auction.EndTime.Sub(now) < s.cfg.AntiSnipeWindow {
+		auction.EndTime = now.Add(s.cfg.AntiSnipeWindow)
+		if err := tx.Model(auction).Updates(map[string]interface{}{
+			"end_time":   auction.EndTime,
+			"updated_at": now,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return bid, nil
+}
+
+// resolveProxyBids raises proxy bids by min_increment on behalf of their
+// owners until no proxy can outbid the current leader. Each round either
+// exhausts a proxy or leaves the leader unbeatable, so it terminates.
+func (s *MarketSVC) resolveProxyBids(tx *gorm.DB, auction *CreditAuction) error {
+	var proxies []AuctionProxyBid
+	if err := tx.Where("auction_id = ?", auction.ID).
+		Order("max_amount DESC, created_at ASC").
+		Find(&proxies).Error; err != nil {
+		return err
+	}
+
+	for {
+		highest, err := highestBid(tx, auction.ID)
+		if err != nil {
+			return err
+		}
+
+		var challenger *AuctionProxyBid
+		for i := range proxies {
+			if highest == nil || proxies[i].BidderID != highest.BidderID {
+				challenger = &proxies[i]
+				break
+			}
+		}
+		if challenger == nil || challenger.MaxAmount < nextMinimumBid(auction, highest) {
+			return nil
+		}
+
+		if highest == nil {
+			if _, err := s.recordBid(tx, auction, challenger.BidderID, auction.StartingPrice, true); err != nil {
+				return err
+			}
+			continue
+		}
+
+		// The leader's own proxy defends up to its cap; on equal caps the
+		// leader keeps the lot.
+		leaderCap := highest.BidAmount
+		for _, p := range proxies {
+			if p.BidderID == highest.BidderID && p.MaxAmount > leaderCap {
+				leaderCap = p.MaxAmount
+			}
+		}
+
+		if challenger.MaxAmount <= leaderCap {
+			amount := math.Min(leaderCap, challenger.MaxAmount+auction.MinIncrement)
+			_, err := s.recordBid(tx, auction, highest.BidderID, amount, true)
+			return err
+		}
+
+		if leaderCap > highest.BidAmount {
+			if _, err := s.recordBid(tx, auction, highest.BidderID, leaderCap, true); err != nil {
+				return err
+			}
+		}
+		amount := math.Min(challenger.MaxAmount, leaderCap+auction.MinIncrement)
+		if _, err := s.recordBid(tx, auction, challenger.BidderID, amount, true); err != nil {
+			return err
+		}
+	}
+}
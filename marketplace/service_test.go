@@ -0,0 +1,562 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func newMockDB(t *testing.T) (*gorm.DB, sqlmock.Sqlmock) {
+	t.Helper()
+
+	sqlDB, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("sqlmock.New: %v", err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		t.Fatalf("gorm.Open: %v", err)
+	}
+
+	return db, mock
+}
+
+// expectListable expects checkListable to load a batch whose land and seller
+// are verified.
+func expectListable(mock sqlmock.Sqlmock, creditID uuid.UUID) {
+	landID := uuid.New()
+	sellerID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "land_id"}).AddRow(creditID, landID))
+	mock.ExpectQuery(`SELECT \* FROM "lands"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "verification_status"}).AddRow(landID, sellerID, "verified"))
+	mock.ExpectQuery(`SELECT \* FROM "sellers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "verification_status"}).AddRow(sellerID, "verified"))
+}
+
+// expectReserve expects ReserveCredits to hold credits on an active listing
+// with nothing else held, and to attach the hold's payment intent.
+func expectReserve(mock sqlmock.Sqlmock, listingID, creditID, reservationID uuid.UUID) {
+	listingRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "carbon_credits_id", "price_per_credit", "quantity", "minimum_purchase", "status"}).
+			AddRow(listingID, creditID, 20.0, 10.0, 1.0, "active")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "credit_listings"`).WillReturnRows(listingRow())
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "credits_available"}).AddRow(creditID, 100.0))
+	expectListable(mock, creditID)
+	mock.ExpectQuery(`SELECT \* FROM "credit_listings"`).WillReturnRows(listingRow())
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "credit_reservations"`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(0.0))
+	mock.ExpectQuery(`INSERT INTO "credit_reservations"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(reservationID))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "credit_reservations" SET "payment_intent_id"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func TestPlaceOrderRefundsWhenPurchaseFails(t *testing.T) {
+	db, mock := newMockDB(t)
+	payments := NewFakePaymentProvider("secret")
+	svc := NewMarketSVC(db, MarketConfig{ReservationTTL: time.Minute}, payments)
+
+	buyerID := uuid.New()
+	listingID := uuid.New()
+	creditID := uuid.New()
+	reservationID := uuid.New()
+
+	expectReserve(mock, listingID, creditID, reservationID)
+
+	// The buyer is charged between the two transactions, with no lock held
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "credit_reservations"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "carbon_credits_id"}).AddRow(reservationID, creditID))
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "credits_available"}).AddRow(creditID, 100.0))
+	mock.ExpectQuery(`SELECT \* FROM "credit_reservations" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "listing_id", "carbon_credits_id", "buyer_id", "amount", "price_per_credit", "status", "expires_at"}).
+			AddRow(reservationID, listingID, creditID, buyerID, 5.0, 20.0, "held", time.Now().Add(time.Minute)))
+	mock.ExpectQuery(`SELECT \* FROM "credit_listings"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "carbon_credits_id", "price_per_credit", "quantity", "minimum_purchase", "status"}).
+			AddRow(listingID, creditID, 20.0, 10.0, 1.0, "active"))
+	mock.ExpectExec(`UPDATE "carbon_credits"`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	// refundCheckout claims the refund, then PlaceOrder releases the hold,
+	// which the claim already did
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "credit_reservations" SET .*"refunded_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "credit_reservations" SET "status"`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err := svc.PlaceOrder(context.Background(), buyerID, listingID, PlaceOrderRequest{Amount: 5})
+	if err == nil {
+		t.Fatal("PlaceOrder succeeded, want the database error")
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(payments.intents) != 1 {
+		t.Fatalf("got %d payment intents, want 1", len(payments.intents))
+	}
+	for id, intent := range payments.intents {
+		if intent.Status != "refunded" {
+			t.Fatalf("intent %s status = %q, want refunded", id, intent.Status)
+		}
+	}
+}
+
+func TestPlaceOrderDoesNotChargeWhenCreditsRunOut(t *testing.T) {
+	db, mock := newMockDB(t)
+	payments := NewFakePaymentProvider("secret")
+	svc := NewMarketSVC(db, MarketConfig{ReservationTTL: time.Minute}, payments)
+
+	listingID := uuid.New()
+	creditID := uuid.New()
+	listingRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "carbon_credits_id", "price_per_credit", "quantity", "minimum_purchase", "status"}).
+			AddRow(listingID, creditID, 20.0, 10.0, 1.0, "active")
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "credit_listings"`).WillReturnRows(listingRow())
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "credits_available"}).AddRow(creditID, 100.0))
+	expectListable(mock, creditID)
+	mock.ExpectQuery(`SELECT \* FROM "credit_listings"`).WillReturnRows(listingRow())
+	mock.ExpectQuery(`SELECT COALESCE\(SUM\(amount\), 0\) FROM "credit_reservations"`).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(8.0))
+	mock.ExpectRollback()
+
+	_, err := svc.PlaceOrder(context.Background(), uuid.New(), listingID, PlaceOrderRequest{Amount: 5})
+	if !errors.Is(err, ErrInsufficientCredits) {
+		t.Fatalf("PlaceOrder error = %v, want ErrInsufficientCredits", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+	if len(payments.intents) != 0 {
+		t.Fatalf("got %d payment intents, want none", len(payments.intents))
+	}
+}
+
+func TestSetListingStatus(t *testing.T) {
+	expectReleaseHolds := func(mock sqlmock.Sqlmock) {
+		mock.ExpectBegin()
+		mock.ExpectExec(`UPDATE "credit_reservations" SET "status"`).WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+	}
+
+	tests := []struct {
+		name         string
+		from         string
+		to           string
+		quantity     float64
+		expect       func(mock sqlmock.Sqlmock, creditID uuid.UUID)
+		wantErr      error
+		wantQuantity float64
+	}{
+		{name: "publish draft", from: "draft", to: "active", quantity: 10, expect: expectListable, wantQuantity: 10},
+		{name: "publish empty draft", from: "draft", to: "active", quantity: 0, wantErr: ErrInvalidListing},
+		{name: "cancel draft", from: "draft", to: "cancelled", quantity: 10,
+			expect: func(mock sqlmock.Sqlmock, _ uuid.UUID) { expectReleaseHolds(mock) }},
+		{name: "cancel active", from: "active", to: "cancelled", quantity: 10,
+			expect: func(mock sqlmock.Sqlmock, _ uuid.UUID) { expectReleaseHolds(mock) }},
+		{name: "unpublish active", from: "active", to: "draft", quantity: 10, wantErr: ErrInvalidTransition},
+		{name: "sell out", from: "active", to: "sold", quantity: 10, wantErr: ErrInvalidTransition},
+		{name: "reopen sold", from: "sold", to: "active", quantity: 5, wantErr: ErrInvalidTransition},
+		{name: "reopen cancelled", from: "cancelled", to: "draft", wantErr: ErrInvalidTransition},
+		{name: "same status", from: "draft", to: "draft", quantity: 10, wantErr: ErrInvalidTransition},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := newMockDB(t)
+			creditID := uuid.New()
+			if tt.expect != nil {
+				tt.expect(mock, creditID)
+			}
+
+			listing := &CreditListing{ID: uuid.New(), CarbonCreditsID: creditID, Status: tt.from, Quantity: tt.quantity}
+			err := setListingStatus(db, listing, tt.to, time.Now())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("setListingStatus(%s -> %s) error = %v, want %v", tt.from, tt.to, err, tt.wantErr)
+			}
+			if err := mock.ExpectationsWereMet(); err != nil {
+				t.Fatal(err)
+			}
+
+			wantStatus := tt.to
+			if tt.wantErr != nil {
+				wantStatus = tt.from
+			}
+			if listing.Status != wantStatus {
+				t.Fatalf("status = %q, want %q", listing.Status, wantStatus)
+			}
+			if tt.wantErr == nil && listing.Quantity != tt.wantQuantity {
+				t.Fatalf("quantity = %v, want %v", listing.Quantity, tt.wantQuantity)
+			}
+		})
+	}
+}
+
+func TestPullListing(t *testing.T) {
+	db, mock := newMockDB(t)
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "credit_reservations" SET "status"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	listing := &CreditListing{ID: uuid.New(), Status: "active", Quantity: 10}
+	if err := pullListing(db, listing, time.Now()); err != nil {
+		t.Fatalf("pullListing: %v", err)
+	}
+	if listing.Status != "draft" || listing.Quantity != 10 {
+		t.Fatalf("pulled listing = %s with %v credits, want draft with 10", listing.Status, listing.Quantity)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, status := range []string{"draft", "sold", "cancelled"} {
+		listing := &CreditListing{ID: uuid.New(), Status: status}
+		if err := pullListing(db, listing, time.Now()); !errors.Is(err, ErrInvalidTransition) {
+			t.Fatalf("pullListing(%s) error = %v, want ErrInvalidTransition", status, err)
+		}
+	}
+}
+
+func TestValidListingTerms(t *testing.T) {
+	max := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		price   float64
+		minimum float64
+		maximum *float64
+		want    bool
+	}{
+		{"no maximum", 20, 1, nil, true},
+		{"maximum above minimum", 20, 1, max(5), true},
+		{"maximum equals minimum", 20, 5, max(5), true},
+		{"zero price", 0, 1, nil, false},
+		{"negative price", -1, 1, nil, false},
+		{"negative minimum", 20, -1, nil, false},
+		{"maximum below minimum", 20, 5, max(4), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := validListingTerms(tt.price, tt.minimum, tt.maximum); got != tt.want {
+				t.Fatalf("validListingTerms = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSettleAuctionFailsWhenLandLostVerification(t *testing.T) {
+	db, mock := newMockDB(t)
+	payments := NewFakePaymentProvider("secret")
+
+	auction := &CreditAuction{ID: uuid.New(), CarbonCreditsID: uuid.New(), AuctionType: "english", StartingPrice: 10, Quantity: 5, Status: "active"}
+	landID := uuid.New()
+	sellerID := uuid.New()
+
+	mock.ExpectQuery(`SELECT \* FROM "auction_bids"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "auction_id", "bidder_id", "bid_amount"}).
+			AddRow(uuid.New(), auction.ID, uuid.New(), 12.0))
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "land_id"}).AddRow(auction.CarbonCreditsID, landID))
+	mock.ExpectQuery(`SELECT \* FROM "lands"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "verification_status"}).AddRow(landID, sellerID, "rejected"))
+	mock.ExpectQuery(`SELECT \* FROM "sellers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "verification_status"}).AddRow(sellerID, "verified"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "credit_auctions" SET "settlement_error"=\$1,"status"=\$2`).
+		WithArgs(ErrLandNotVerified.Error(), "failed", sqlmock.AnyArg(), auction.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	charged, err := settleAuction(context.Background(), db, payments, auction)
+	if err != nil {
+		t.Fatalf("settleAuction: %v", err)
+	}
+	if charged != nil {
+		t.Fatalf("settleAuction charged %s, want no charge", charged.ID)
+	}
+	if len(payments.intents) != 0 {
+		t.Fatalf("got %d payment intents, want none", len(payments.intents))
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateAuctionRejectsUnverifiedSeller(t *testing.T) {
+	db, mock := newMockDB(t)
+	svc := NewMarketSVC(db, MarketConfig{}, NewFakePaymentProvider("secret"))
+
+	creditID := uuid.New()
+	landID := uuid.New()
+	sellerID := uuid.New()
+
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "carbon_credits"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "land_id"}).AddRow(creditID, landID))
+	mock.ExpectQuery(`SELECT \* FROM "lands"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "verification_status"}).AddRow(landID, sellerID, "verified"))
+	mock.ExpectQuery(`SELECT \* FROM "sellers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "verification_status"}).AddRow(sellerID, "pending"))
+	mock.ExpectRollback()
+
+	now := time.Now()
+	_, err := svc.CreateAuction(context.Background(), uuid.New(), CreateAuctionRequest{
+		CarbonCreditsID: creditID,
+		StartingPrice:   10,
+		MinIncrement:    1,
+		Quantity:        5,
+		StartTime:       now,
+		EndTime:         now.Add(time.Hour),
+	})
+	if !errors.Is(err, ErrSellerNotVerified) {
+		t.Fatalf("CreateAuction error = %v, want ErrSellerNotVerified", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefundPurchaseRetriesFailedPaymentRefund(t *testing.T) {
+	db, mock := newMockDB(t)
+	payments := NewFakePaymentProvider("secret")
+	svc := NewMarketSVC(db, MarketConfig{RefundGracePeriod: time.Hour}, payments)
+	ctx := context.Background()
+
+	// The gateway refuses to refund an unconfirmed intent, standing in for
+	// a gateway outage
+	intent, err := payments.CreateIntent(ctx, 100, paymentCurrency, "purchase")
+	if err != nil {
+		t.Fatalf("CreateIntent: %v", err)
+	}
+
+	buyerID := uuid.New()
+	purchaseID := uuid.New()
+	creditID := uuid.New()
+	purchaseRow := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"id", "buyer_id", "carbon_credits_id", "amount", "price_per_credit", "total_price", "purchase_date", "transaction_hash"}).
+			AddRow(purchaseID, buyerID, creditID, 5.0, 20.0, 100.0, time.Now(), intent.ID)
+	}
+
+	// An auction purchase, with no listing to put the credits back on
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "purchases" .* FOR UPDATE`).WillReturnRows(purchaseRow())
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "credits_available"}).AddRow(creditID, 0.0))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "credit_transfers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`SELECT \* FROM "credit_wallets" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "purchase_id", "credits_remaining"}).AddRow(uuid.New(), buyerID, purchaseID, 5.0))
+	mock.ExpectExec(`UPDATE "credit_wallets"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "carbon_credits"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "purchases" SET "refunded_at"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// The refund is claimed, fails and the claim is given back
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "purchases" SET "payment_refunded_at"=\$1 .*payment_refunded_at IS NULL`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "purchases" SET "payment_refunded_at"=\$1`).
+		WithArgs(nil, purchaseID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	purchase, err := svc.RefundPurchase(ctx, buyerID, purchaseID)
+	if err != nil {
+		t.Fatalf("RefundPurchase: %v", err)
+	}
+	if purchase.RefundedAt == nil || purchase.PaymentRefundedAt != nil {
+		t.Fatalf("purchase refunded at %v, payment refunded at %v, want a reversal with the payment pending", purchase.RefundedAt, purchase.PaymentRefundedAt)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the gateway recovers, the sweeper returns the payment
+	if _, err := payments.ConfirmIntent(ctx, intent.ID); err != nil {
+		t.Fatalf("ConfirmIntent: %v", err)
+	}
+
+	mock.ExpectQuery(`SELECT \* FROM "purchases" WHERE refunded_at IS NOT NULL AND payment_refunded_at IS NULL`).
+		WillReturnRows(purchaseRow())
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "purchases" SET "payment_refunded_at"=\$1 .*payment_refunded_at IS NULL`).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := svc.RetryPaymentRefunds(ctx)
+	if err != nil {
+		t.Fatalf("RetryPaymentRefunds: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("RetryPaymentRefunds refunded %d purchases, want 1", n)
+	}
+	if got := payments.intents[intent.ID].Status; got != "refunded" {
+		t.Fatalf("intent status = %q, want refunded", got)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRefundPurchaseRefusesTransferredCredits(t *testing.T) {
+	db, mock := newMockDB(t)
+	payments := NewFakePaymentProvider("secret")
+	svc := NewMarketSVC(db, MarketConfig{RefundGracePeriod: time.Hour}, payments)
+
+	buyerID := uuid.New()
+	purchaseID := uuid.New()
+	creditID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "purchases" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "buyer_id", "carbon_credits_id", "amount", "purchase_date"}).
+			AddRow(purchaseID, buyerID, creditID, 5.0, time.Now()))
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(creditID))
+	mock.ExpectQuery(`SELECT count\(\*\) FROM "credit_transfers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectRollback()
+
+	if _, err := svc.RefundPurchase(context.Background(), buyerID, purchaseID); !errors.Is(err, ErrCreditsInUse) {
+		t.Fatalf("RefundPurchase error = %v, want ErrCreditsInUse", err)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPullUnlistableListings(t *testing.T) {
+	db, mock := newMockDB(t)
+	svc := NewMarketSVC(db, MarketConfig{}, NewFakePaymentProvider("secret"))
+
+	creditID := uuid.New()
+	landID := uuid.New()
+	sellerID := uuid.New()
+	listingID := uuid.New()
+
+	mock.ExpectQuery(`SELECT DISTINCT "credit_listings"."carbon_credits_id" FROM "credit_listings"`).
+		WillReturnRows(sqlmock.NewRows([]string{"carbon_credits_id"}).AddRow(creditID))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(creditID))
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "land_id"}).AddRow(creditID, landID))
+	mock.ExpectQuery(`SELECT \* FROM "lands"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "owner_id", "verification_status"}).AddRow(landID, sellerID, "rejected"))
+	mock.ExpectQuery(`SELECT \* FROM "sellers"`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "verification_status"}).AddRow(sellerID, "verified"))
+	mock.ExpectQuery(`SELECT \* FROM "credit_listings" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "carbon_credits_id", "quantity", "status"}).AddRow(listingID, creditID, 10.0, "active"))
+	mock.ExpectExec(`UPDATE "credit_reservations" SET "status"`).
+		WithArgs("released", sqlmock.AnyArg(), listingID, "held").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE "credit_listings" SET "status"=\$1`).
+		WithArgs("draft", sqlmock.AnyArg(), listingID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	n, err := svc.PullUnlistableListings(context.Background())
+	if err != nil {
+		t.Fatalf("PullUnlistableListings: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("pulled %d listings, want 1", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPullUnlistableListingsSkipsReverifiedBatch(t *testing.T) {
+	db, mock := newMockDB(t)
+	svc := NewMarketSVC(db, MarketConfig{}, NewFakePaymentProvider("secret"))
+
+	creditID := uuid.New()
+
+	// Verified again between the scan and the batch lock
+	mock.ExpectQuery(`SELECT DISTINCT "credit_listings"."carbon_credits_id" FROM "credit_listings"`).
+		WillReturnRows(sqlmock.NewRows([]string{"carbon_credits_id"}).AddRow(creditID))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "carbon_credits" .* FOR UPDATE`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(creditID))
+	expectListable(mock, creditID)
+	mock.ExpectCommit()
+
+	n, err := svc.PullUnlistableListings(context.Background())
+	if err != nil {
+		t.Fatalf("PullUnlistableListings: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("pulled %d listings, want none", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSettleDueRecordsFailureAndMovesOn(t *testing.T) {
+	db, mock := newMockDB(t)
+	settler := NewAuctionSettler(db, NewFakePaymentProvider("secret"), time.Minute)
+
+	auctionID := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "credit_auctions" .* FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "auction_type", "quantity", "status"}).AddRow(auctionID, "english", 5.0, "active"))
+	mock.ExpectQuery(`SELECT \* FROM "auction_bids"`).WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "credit_auctions" SET "settlement_attempts"=settlement_attempts \+ 1,"settlement_error"=\$1,"status"=CASE WHEN settlement_attempts \+ 1 >= \$2 THEN 'failed' ELSE status END`).
+		WithArgs("connection reset", maxSettlementAttempts, sqlmock.AnyArg(), auctionID, "pending", "active").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	// The failed auction is skipped for the rest of the run
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT \* FROM "credit_auctions" .* id NOT IN \(\$\d+\) .* FOR UPDATE SKIP LOCKED`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+
+	n, err := settler.SettleDue(context.Background())
+	if err != nil {
+		t.Fatalf("SettleDue: %v", err)
+	}
+	if n != 0 {
+		t.Fatalf("settled %d auctions, want none", n)
+	}
+	if err := mock.ExpectationsWereMet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxSettlementAttempts is how many times settling an auction may fail before
+// it is marked failed and left for an operator.
+const maxSettlementAttempts = 5
+
+// AuctionSettler periodically closes auctions whose end_time has passed.
+// Every auction is settled in its own transaction and claimed with
+// FOR UPDATE SKIP LOCKED, so several service instances can run it at once
+// without settling the same auction twice.
+type AuctionSettler struct {
+	db       *gorm.DB
+	payments PaymentProvider
+	interval time.Duration
+}
+
+func NewAuctionSettler(db *gorm.DB, payments PaymentProvider, interval time.Duration) *AuctionSettler {
+	return &AuctionSettler{db: db, payments: payments, interval: interval}
+}
+
+func (s *AuctionSettler) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := s.SettleDue(ctx); err != nil {
+			log.Printf("auction settlement failed: %v", err)
+		} else if n > 0 {
+			log.Printf("settled %d auctions", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SettleDue settles due auctions one at a time until none are left and
+// returns how many were settled. An auction that fails to settle is recorded
+// and skipped for the rest of the run, so it cannot hold up the others.
+func (s *AuctionSettler) SettleDue(ctx context.Context) (int, error) {
+	settled := 0
+	var failed []uuid.UUID
+	for {
+		id, err := s.settleNext(ctx, failed)
+		if err != nil {
+			if id == nil {
+				return settled, err
+			}
+			log.Printf("settling auction %s failed: %v", *id, err)
+			if rerr := s.recordFailure(ctx, *id, err); rerr != nil {
+				log.Printf("recording settlement failure of auction %s failed: %v", *id, rerr)
+			}
+			failed = append(failed, *id)
+			continue
+		}
+		if id == nil {
+			return settled, nil
+		}
+		settled++
+	}
+}
+
+// settleNext settles the earliest due auction not in skip and returns its ID,
+// or nil when none is due. The ID is also returned when settling it failed.
+func (s *AuctionSettler) settleNext(ctx context.Context, skip []uuid.UUID) (*uuid.UUID, error) {
+	var id *uuid.UUID
+	var charged *PaymentIntent
+
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status IN ? AND end_time <= ?", []string{"pending", "active"}, time.Now())
+		if len(skip) > 0 {
+			query = query.Where("id NOT IN ?", skip)
+		}
+
+		var auction CreditAuction
+		err := query.Order("end_time ASC").First(&auction).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		id = &auction.ID
+		charged, err = settleAuction(ctx, tx, s.payments, &auction)
+		return err
+	})
+
+	if err != nil && charged != nil {
+		refundCharges(ctx, s.payments, []*PaymentIntent{charged})
+	}
+
+	return id, err
+}
+
+// recordFailure counts a failed settlement attempt and marks the auction
+// failed once it has used up its attempts, which also releases its lot.
+func (s *AuctionSettler) recordFailure(ctx context.Context, auctionID uuid.UUID, cause error) error {
+	return s.db.WithContext(ctx).
+		Model(&CreditAuction{}).
+		Where("id = ? AND status IN ?", auctionID, []string{"pending", "active"}).
+		Updates(map[string]interface{}{
+			"settlement_attempts": gorm.Expr("settlement_attempts + 1"),
+			"settlement_error":    cause.Error(),
+			"status":              gorm.Expr("CASE WHEN settlement_attempts + 1 >= ? THEN 'failed' ELSE status END", maxSettlementAttempts),
+			"updated_at":          time.Now(),
+		}).Error
+}
+
+// settleAuction awards the auction's lot to the highest bidder, or cancels
+// the auction when there are no bids, the reserve price was not met or the
+// winner's payment is declined, and fails it when the lot's credits may no
+// longer be sold. Bids are prices per credit; sealed
+// second-price auctions charge the best competing bid (or the
+// reserve/starting price) instead of the winning one. It returns the winner's
+// payment so the caller can refund it if the transaction does not commit.
+func settleAuction(ctx context.Context, tx *gorm.DB, payments PaymentProvider, auction *CreditAuction) (*PaymentIntent, error) {
+	highest, err := highestBid(tx, auction.ID)
+	if err != nil {
+		return nil, err
+	}
+	if highest == nil {
+		return nil, closeAuction(tx, auction, "cancelled", time.Now())
+	}
+
+	if auction.ReservePrice != nil && highest.BidAmount < *auction.ReservePrice {
+		return nil, closeAuction(tx, auction, "cancelled", time.Now())
+	}
+
+	price := highest.BidAmount
+	if auction.AuctionType == "sealed_second_price" {
+		price = auction.StartingPrice
+
+		var second AuctionBid
+		err := tx.Where("auction_id = ? AND bidder_id <> ?", auction.ID, highest.BidderID).
+			Order("bid_amount DESC").
+			First(&second).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		if err == nil {
+			price = second.BidAmount
+		}
+
+		if auction.ReservePrice != nil && price < *auction.ReservePrice {
+			price = *auction.ReservePrice
+		}
+	}
+
+	charged, err := awardAuction(ctx, tx, payments, auction, highest.BidderID, price)
+	switch {
+	case errors.Is(err, ErrPaymentFailed):
+		return nil, closeAuction(tx, auction, "cancelled", time.Now())
+	case isUnlistable(err):
+		return nil, failAuction(tx, auction, err, time.Now())
+	}
+	return charged, err
+}
+
+// awardAuction charges the winner for the auction's lot at the given price per
+// credit, hands the lot over and completes the auction. Auctions created
+// before lots were set aside may have an empty lot and are cancelled instead.
+// The lot is already kept from every other sale, so the winner is charged
+// before the batch is locked and a slow gateway holds up no buyer of the
+// batch. Nothing is written when the payment fails or the lot's credits may
+// no longer be sold.
+func awardAuction(ctx context.Context, tx *gorm.DB, payments PaymentProvider, auction *CreditAuction, winnerID uuid.UUID, price float64) (*PaymentIntent, error) {
+	now := time.Now()
+
+	amount := auction.Quantity
+	if amount <= 0 {
+		return nil, closeAuction(tx, auction, "cancelled", now)
+	}
+
+	// The land or seller may have lost verification, or the batch expired,
+	// while bidding ran
+	if err := checkListable(tx, auction.CarbonCreditsID); err != nil {
+		return nil, err
+	}
+
+	intent, err := chargePayment(ctx, payments, amount*price, auction.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	var credit CarbonCredit
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", auction.CarbonCreditsID).
+		First(&credit).Error; err != nil {
+		return intent, err
+	}
+
+	if err := tx.Model(&credit).Updates(map[string]interface{}{
+		"credits_available": gorm.Expr("credits_available - ?", amount),
+		"credits_sold":      gorm.Expr("credits_sold + ?", amount),
+	}).Error; err != nil {
+		return intent, err
+	}
+
+	purchase := Purchase{
+		BuyerID:         winnerID,
+		CarbonCreditsID: credit.ID,
+		AuctionID:       &auction.ID,
+		Amount:          amount,
+		PricePerCredit:  price,
+		TotalPrice:      amount * price,
+		PurchaseDate:    now,
+		TransactionHash: &intent.ID,
+	}
+	if err := tx.Create(&purchase).Error; err != nil {
+		return intent, err
+	}
+
+	wallet := CreditWallet{
+		OwnerID:          winnerID,
+		PurchaseID:       purchase.ID,
+		CreditsRemaining: amount,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	if err := tx.Create(&wallet).Error; err != nil {
+		return intent, err
+	}
+
+	return intent, closeAuction(tx, auction, "completed", now)
+}
+
+func closeAuction(tx *gorm.DB, auction *CreditAuction, status string, now time.Time) error {
+	return tx.Model(auction).Updates(map[string]interface{}{
+		"status":     status,
+		"updated_at": now,
+	}).Error
+}
+
+// failAuction closes an auction that cannot be settled, recording why, which
+// also releases its lot.
+func failAuction(tx *gorm.DB, auction *CreditAuction, cause error, now time.Time) error {
+	return tx.Model(auction).Updates(map[string]interface{}{
+		"status":           "failed",
+		"settlement_error": cause.Error(),
+		"updated_at":       now,
+	}).Error
+}
+
+// runReservationSweeper periodically releases checkout holds past their TTL.
+func runReservationSweeper(ctx context.Context, svc MarketplaceService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if n, err := svc.ReleaseExpiredReservations(ctx); err != nil {
+			log.Printf("releasing expired reservations failed: %v", err)
+		} else if n > 0 {
+			log.Printf("released %d expired reservations", n)
+		}
+	}
+}
+
+// runListingSweeper periodically pulls active listings whose credits may no
+// longer be offered.
+func runListingSweeper(ctx context.Context, svc MarketplaceService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if n, err := svc.PullUnlistableListings(ctx); err != nil {
+			log.Printf("pulling unlistable listings failed: %v", err)
+		} else if n > 0 {
+			log.Printf("pulled %d unlistable listings", n)
+		}
+	}
+}
+
+// runRefundSweeper periodically retries gateway refunds of reversed purchases
+// that failed the first time.
+func runRefundSweeper(ctx context.Context, svc MarketplaceService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if n, err := svc.RetryPaymentRefunds(ctx); err != nil {
+			log.Printf("retrying payment refunds failed: %v", err)
+		} else if n > 0 {
+			log.Printf("refunded %d pending purchase payments", n)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// summaryCache keeps the last computed MarketSummary. The mutex is held while
+// a stale summary is rebuilt, so concurrent requests wait for one rebuild
+// instead of each querying Postgres.
+type summaryCache struct {
+	mu      sync.Mutex
+	summary *MarketSummary
+	expires time.Time
+}
+
+// candleIntervals maps the accepted intervals to date_trunc fields.
+var candleIntervals = map[string]string{
+	"day":   "day",
+	"week":  "week",
+	"month": "month",
+}
+
+// GetPriceCandles buckets completed purchases into open/high/low/close/volume
+// candles by purchase date, oldest bucket first. Refunded purchases are left
+// out. Open and close are the first and last trade prices in the bucket.
+func (s *MarketSVC) GetPriceCandles(ctx context.Context, q CandleQuery) ([]Candle, error) {
+	field, ok := candleIntervals[q.Interval]
+	if !ok {
+		return nil, ErrInvalidInterval
+	}
+
+	bucket := "date_trunc('" + field + "', purchases.purchase_date)"
+
+	query := s.db.WithContext(ctx).
+		Table("purchases").
+		Select(bucket + ` AS bucket,
+			(array_agg(purchases.price_per_credit ORDER BY purchases.purchase_date ASC, purchases.id ASC))[1] AS open,
+			MAX(purchases.price_per_credit) AS high,
+			MIN(purchases.price_per_credit) AS low,
+			(array_agg(purchases.price_per_credit ORDER BY purchases.purchase_date DESC, purchases.id DESC))[1] AS close,
+			SUM(purchases.amount) AS volume,
+			COUNT(*) AS trades`).
+		Joins("JOIN carbon_credits ON carbon_credits.id = purchases.carbon_credits_id").
+		Joins("JOIN lands ON lands.id = carbon_credits.land_id").
+		Where("purchases.refunded_at IS NULL")
+
+	if q.BiomeType != nil {
+		query = query.Where("lands.biome_type = ?", *q.BiomeType)
+	}
+	if q.Location != nil {
+		query = query.Where("lands.location = ?", *q.Location)
+	}
+	if q.VintageYear != nil {
+		query = query.Where("carbon_credits.vintage_year = ?", *q.VintageYear)
+	}
+	if q.VerificationStandard != nil {
+		query = query.Where("carbon_credits.verification_standard = ?", *q.VerificationStandard)
+	}
+	if q.From != nil {
+		query = query.Where("purchases.purchase_date >= ?", *q.From)
+	}
+	if q.To != nil {
+		query = query.Where("purchases.purchase_date < ?", *q.To)
+	}
+
+	var candles []Candle
+	if err := query.Group(bucket).Order(bucket + " ASC").Scan(&candles).Error; err != nil {
+		return nil, err
+	}
+
+	return candles, nil
+}
+
+// summaryRebuildTimeout bounds a market summary rebuild, which runs detached
+// from the request that started it.
+const summaryRebuildTimeout = 10 * time.Second
+
+// GetMarketSummary returns the cached market summary, rebuilding it once it is
+// older than SummaryCacheTTL. Callers queue on the cache while it is rebuilt,
+// so the rebuild does not stop when the first caller's request is cancelled.
+func (s *MarketSVC) GetMarketSummary(ctx context.Context) (*MarketSummary, error) {
+	s.summary.mu.Lock()
+	defer s.summary.mu.Unlock()
+
+	now := time.Now()
+	if s.summary.summary != nil && now.Before(s.summary.expires) {
+		return s.summary.summary, nil
+	}
+
+	rebuildCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), summaryRebuildTimeout)
+	defer cancel()
+
+	summary, err := buildMarketSummary(s.db.WithContext(rebuildCtx), now)
+	if err != nil {
+		return nil, err
+	}
+
+	s.summary.summary = summary
+	s.summary.expires = now.Add(s.cfg.SummaryCacheTTL)
+	return summary, nil
+}
+
+func buildMarketSummary(db *gorm.DB, now time.Time) (*MarketSummary, error) {
+	summary := &MarketSummary{GeneratedAt: now}
+
+	if err := db.Model(&CreditListing{}).
+		Where("status = ?", "active").
+		Count(&summary.ActiveListings).Error; err != nil {
+		return nil, err
+	}
+
+	if err := db.Model(&CreditListing{}).
+		Where("status = ?", "active").
+		Select("COALESCE(SUM(quantity), 0)").
+		Scan(&summary.CreditsOnOffer).Error; err != nil {
+		return nil, err
+	}
+
+	windows := []struct {
+		since  time.Duration
+		volume *TradingVolume
+	}{
+		{24 * time.Hour, &summary.Last24h},
+		{7 * 24 * time.Hour, &summary.Last7d},
+		{30 * 24 * time.Hour, &summary.Last30d},
+	}
+	for _, w := range windows {
+		if err := db.Model(&Purchase{}).
+			Select(`COALESCE(SUM(amount), 0) AS credits,
+				COALESCE(SUM(total_price), 0) AS value,
+				COUNT(*) AS trades,
+				COALESCE(SUM(price_per_credit * amount) / NULLIF(SUM(amount), 0), 0) AS vwap`).
+			Where("refunded_at IS NULL AND purchase_date >= ?", now.Add(-w.since)).
+			Scan(w.volume).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := db.Model(&CreditListing{}).
+		Select("lands.biome_type, MIN(credit_listings.price_per_credit) AS price_per_credit").
+		Joins("JOIN carbon_credits ON carbon_credits.id = credit_listings.carbon_credits_id").
+		Joins("JOIN lands ON lands.id = carbon_credits.land_id").
+		Where("credit_listings.status = ?", "active").
+		Group("lands.biome_type").
+		Order("lands.biome_type ASC").
+		Scan(&summary.BestAsks).Error; err != nil {
+		return nil, err
+	}
+
+	return summary, nil
+}
@@ -13,66 +13,346 @@ type DBCreds struct {
 	Password string `json:"password"`
 }
 
+type MarketConfig struct {
+	// Bids landing this close to end_time push it out to now + AntiSnipeWindow
+	AntiSnipeWindow time.Duration
+	// How long a checkout reservation holds credits before it lapses
+	ReservationTTL time.Duration
+	// How long after a purchase the buyer may still cancel it for a refund
+	RefundGracePeriod time.Duration
+	// How long a computed market summary is served before it is rebuilt
+	SummaryCacheTTL time.Duration
+}
+
 var (
-	ErrNotFound     = errors.New("listing not found")
-	ErrUnauthorized = errors.New("unauthorized access")
+	ErrNotFound                = errors.New("listing not found")
+	ErrUnauthorized            = errors.New("unauthorized access")
+	ErrInvalidAmount           = errors.New("amount outside listing purchase limits")
+	ErrInsufficientCredits     = errors.New("not enough credits available")
+	ErrAuctionNotFound         = errors.New("auction not found")
+	ErrInvalidAuction          = errors.New("invalid auction parameters")
+	ErrAuctionClosed           = errors.New("auction is not open for bidding")
+	ErrBidTooLow               = errors.New("bid is below the minimum accepted amount")
+	ErrUnsupportedBid          = errors.New("bid type not supported for this auction")
+	ErrBuyOrderNotFound        = errors.New("buy order not found")
+	ErrInvalidBuyOrder         = errors.New("invalid buy order parameters")
+	ErrReservationNotFound     = errors.New("reservation not found")
+	ErrReservationExpired      = errors.New("reservation has expired")
+	ErrPaymentFailed           = errors.New("payment failed")
+	ErrInvalidWebhookSignature = errors.New("invalid webhook signature")
+	ErrPurchaseNotFound        = errors.New("purchase not found")
+	ErrRefundWindowClosed      = errors.New("refund grace period has passed")
+	ErrCreditsInUse            = errors.New("credits already retired or transferred")
+	ErrWalletNotFound          = errors.New("wallet entry not found")
+	ErrInvalidRetirement       = errors.New("invalid retirement parameters")
+	ErrRetirementNotFound      = errors.New("retirement not found")
+	ErrInvalidListing          = errors.New("invalid listing parameters")
+	ErrInvalidTransfer         = errors.New("invalid transfer parameters")
+	ErrInvalidInterval         = errors.New("invalid interval: must be day, week or month")
+	ErrInvalidCursor           = errors.New("invalid cursor")
+	ErrInvalidSort             = errors.New("invalid sort: must be price, createdAt, vintage, available or distance (with near), optionally prefixed with -")
+	ErrRecipientNotFound       = errors.New("recipient not found")
+	ErrInvalidStatus           = errors.New("invalid listing status: must be draft or active")
+	ErrInvalidTransition       = errors.New("listing status transition not allowed")
+	ErrCreditNotFound          = errors.New("carbon credit batch not found")
+	ErrLandNotFound            = errors.New("land not found")
+	ErrLandNotVerified         = errors.New("land is not verified")
+	ErrSellerNotVerified       = errors.New("seller is not verified")
+	ErrCreditsExpired          = errors.New("carbon credits have expired")
 )
 
+// PageRequest asks for the page after Cursor, or the first page when Cursor
+// is empty.
+type PageRequest struct {
+	Cursor    string
+	Limit     int
+	WithTotal bool
+}
+
+type Page[T any] struct {
+	Items []T `json:"items"`
+	// Pass as cursor to get the next page; null on the last page
+	NextCursor *string `json:"nextCursor"`
+	// Number of matching rows across all pages, only when requested
+	Total *int64 `json:"total,omitempty"`
+}
+
 type FilterOptions struct {
-	MinPrice   *float64 `json:"minPrice,omitempty"`
-	MaxPrice   *float64 `json:"maxPrice,omitempty"`
-	MinCredits *float64 `json:"minCredits,omitempty"`
-	MaxCredits *float64 `json:"maxCredits,omitempty"`
-	BiomeType  *string  `json:"biomeType,omitempty"`
-	Location   *string  `json:"location,omitempty"`
+	MinPrice               *float64   `json:"minPrice,omitempty"`
+	MaxPrice               *float64   `json:"maxPrice,omitempty"`
+	MinCredits             *float64   `json:"minCredits,omitempty"`
+	MaxCredits             *float64   `json:"maxCredits,omitempty"`
+	BiomeType              *string    `json:"biomeType,omitempty"`
+	Location               *string    `json:"location,omitempty"`
+	MinVintageYear         *int       `json:"minVintageYear,omitempty"`
+	MaxVintageYear         *int       `json:"maxVintageYear,omitempty"`
+	VerificationStandard   *string    `json:"verificationStandard,omitempty"`
+	ExpiresAfter           *time.Time `json:"expiresAfter,omitempty"`
+	LandVerificationStatus *string    `json:"landVerificationStatus,omitempty"`
+	MinForestDensity       *float64   `json:"minForestDensity,omitempty"`
+	// Matches lands with any of these species, ignoring case
+	TreeSpecies []string `json:"treeSpecies,omitempty"`
+	// Free-text search over the land's title, description, location and
+	// tree species
+	Query *string `json:"q,omitempty"`
+	// Near sorts results by distance from a point, and with RadiusKm also
+	// limits them to that radius
+	Near     *GeoPoint    `json:"near,omitempty"`
+	RadiusKm *float64     `json:"radiusKm,omitempty"`
+	BBox     *BoundingBox `json:"bbox,omitempty"`
+	// One of price, createdAt, vintage, available or distance, prefixed with
+	// - for descending order
+	Sort *string `json:"sort,omitempty"`
+}
+
+// LandFeatureCollection is a GeoJSON FeatureCollection with one Point
+// feature per land.
+type LandFeatureCollection struct {
+	Type     string        `json:"type" example:"FeatureCollection"`
+	Features []LandFeature `json:"features"`
+}
+
+type LandFeature struct {
+	Type       string                `json:"type" example:"Feature"`
+	ID         uuid.UUID             `json:"id"`
+	Geometry   PointGeometry         `json:"geometry"`
+	Properties LandFeatureProperties `json:"properties"`
+}
+
+type PointGeometry struct {
+	Type string `json:"type" example:"Point"`
+	// Longitude, latitude as GeoJSON orders them
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+type LandFeatureProperties struct {
+	Title                   string   `json:"title"`
+	Location                string   `json:"location"`
+	BiomeType               string   `json:"biomeType"`
+	SizeSquareMeters        float64  `json:"sizeSquareMeters"`
+	VerificationStatus      string   `json:"verificationStatus"`
+	ForestDensityPercentage *float64 `json:"forestDensityPercentage"`
+	ActiveListings          int64    `json:"activeListings"`
+	// Lowest price per credit across the land's active listings, null when
+	// nothing is listed
+	BestPricePerCredit *float64 `json:"bestPricePerCredit"`
+}
+
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+type BoundingBox struct {
+	MinLon float64 `json:"minLon"`
+	MinLat float64 `json:"minLat"`
+	MaxLon float64 `json:"maxLon"`
+	MaxLat float64 `json:"maxLat"`
+}
+
+// CandleQuery selects the purchases that make up a price history. Interval is
+// day, week or month.
+type CandleQuery struct {
+	Interval             string
+	BiomeType            *string
+	Location             *string
+	VintageYear          *int
+	VerificationStandard *string
+	From                 *time.Time
+	To                   *time.Time
+}
+
+type Candle struct {
+	Bucket time.Time `json:"bucket"`
+	Open   float64   `json:"open"`
+	High   float64   `json:"high"`
+	Low    float64   `json:"low"`
+	Close  float64   `json:"close"`
+	Volume float64   `json:"volume"`
+	Trades int64     `json:"trades"`
+}
+
+// MarketSummary is the dashboard overview of the marketplace.
+type MarketSummary struct {
+	ActiveListings int64         `json:"activeListings"`
+	CreditsOnOffer float64       `json:"creditsOnOffer"`
+	Last24h        TradingVolume `json:"last24h"`
+	Last7d         TradingVolume `json:"last7d"`
+	Last30d        TradingVolume `json:"last30d"`
+	BestAsks       []BiomeAsk    `json:"bestAsks"`
+	GeneratedAt    time.Time     `json:"generatedAt"`
+}
+
+type TradingVolume struct {
+	Credits float64 `json:"credits"`
+	Value   float64 `json:"value"`
+	Trades  int64   `json:"trades"`
+	// Volume-weighted average price per credit, 0 without trades
+	VWAP float64 `json:"vwap"`
+}
+
+type BiomeAsk struct {
+	BiomeType      string  `json:"biomeType"`
+	PricePerCredit float64 `json:"pricePerCredit"`
 }
 
 type CreateListingRequest struct {
 	CarbonCreditsID uuid.UUID `json:"carbonCreditsId"`
-	PricePerCredit  float64   `json:"pricePerCredit"`
-	MinimumPurchase float64   `json:"minimumPurchase"`
-	MaximumPurchase *float64  `json:"maximumPurchase,omitempty"`
-	Status          string    `json:"status"`
+	// Credits allocated from the batch to this listing
+	Quantity        float64  `json:"quantity"`
+	PricePerCredit  float64  `json:"pricePerCredit"`
+	MinimumPurchase float64  `json:"minimumPurchase"`
+	MaximumPurchase *float64 `json:"maximumPurchase,omitempty"`
+	// draft (default) or active
+	Status string `json:"status"`
+}
+
+type CreateResaleListingRequest struct {
+	Quantity        float64  `json:"quantity"`
+	PricePerCredit  float64  `json:"pricePerCredit"`
+	MinimumPurchase float64  `json:"minimumPurchase"`
+	MaximumPurchase *float64 `json:"maximumPurchase,omitempty"`
 }
 
 type UpdateListingRequest struct {
 	PricePerCredit  float64  `json:"pricePerCredit"`
 	MinimumPurchase float64  `json:"minimumPurchase"`
 	MaximumPurchase *float64 `json:"maximumPurchase,omitempty"`
-	Status          string   `json:"status"`
+	// Optional; changes the credits allocated from the batch
+	Quantity *float64 `json:"quantity,omitempty"`
+	// Optional; active (publishing a draft) or cancelled
+	Status *string `json:"status,omitempty"`
+}
+
+type PlaceOrderRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+type ReserveCreditsRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+type TransferCreditsRequest struct {
+	RecipientID uuid.UUID `json:"recipientId"`
+	Quantity    float64   `json:"quantity"`
+}
+
+type RetireCreditsRequest struct {
+	Quantity        float64 `json:"quantity"`
+	BeneficiaryName string  `json:"beneficiaryName"`
+	Reason          *string `json:"reason,omitempty"`
+}
+
+// WalletHolding aggregates a buyer's wallet entries that share a vintage,
+// biome and originating land.
+type WalletHolding struct {
+	VintageYear           *int      `json:"vintageYear"`
+	BiomeType             string    `json:"biomeType"`
+	LandID                uuid.UUID `json:"landId"`
+	LandTitle             string    `json:"landTitle"`
+	Purchased             float64   `json:"purchased"`
+	Remaining             float64   `json:"remaining"`
+	Retired               float64   `json:"retired"`
+	AveragePricePerCredit float64   `json:"averagePricePerCredit"`
+}
+
+type CertificateVerification struct {
+	CertificateID        string    `json:"certificateId"`
+	Valid                bool      `json:"valid"`
+	BeneficiaryName      string    `json:"beneficiaryName"`
+	Quantity             float64   `json:"quantity"`
+	VintageYear          *int      `json:"vintageYear,omitempty"`
+	VerificationStandard *string   `json:"verificationStandard,omitempty"`
+	LandTitle            string    `json:"landTitle"`
+	Location             string    `json:"location"`
+	RetiredAt            time.Time `json:"retiredAt"`
 }
 
 type CreateAuctionRequest struct {
 	CarbonCreditsID uuid.UUID `json:"carbonCreditsId"`
-	StartingPrice   float64   `json:"startingPrice"`
-	ReservePrice    *float64  `json:"reservePrice,omitempty"`
-	MinIncrement    float64   `json:"minIncrement"`
-	StartTime       time.Time `json:"startTime"`
-	EndTime         time.Time `json:"endTime"`
+	// Credits set aside from the batch and sold to the winner
+	Quantity float64 `json:"quantity"`
+	// english (default), sealed_second_price or dutch
+	AuctionType   string   `json:"auctionType,omitempty"`
+	StartingPrice float64  `json:"startingPrice"`
+	ReservePrice  *float64 `json:"reservePrice,omitempty"`
+	// Bid step for English auctions, price drop per interval for Dutch ones
+	MinIncrement             float64   `json:"minIncrement"`
+	PriceDropIntervalSeconds *int      `json:"priceDropIntervalSeconds,omitempty"`
+	StartTime                time.Time `json:"startTime"`
+	EndTime                  time.Time `json:"endTime"`
+}
+
+type PlaceBidRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+type ProxyBidRequest struct {
+	MaxAmount float64 `json:"maxAmount"`
+}
+
+type CreateBuyOrderRequest struct {
+	Quantity             float64 `json:"quantity"`
+	MaxPricePerCredit    float64 `json:"maxPricePerCredit"`
+	BiomeType            *string `json:"biomeType,omitempty"`
+	Location             *string `json:"location,omitempty"`
+	MinVintageYear       *int    `json:"minVintageYear,omitempty"`
+	VerificationStandard *string `json:"verificationStandard,omitempty"`
 }
 
 type MarketplaceService interface {
 	// Listing operations
 	GetSellerListingByID(ctx context.Context, userID uuid.UUID, listingID uuid.UUID) (*CreditListing, error)
-	GetSellerListings(ctx context.Context, userID uuid.UUID, page, limit int) ([]CreditListing, error)
-	GetActiveListings(ctx context.Context, filter *FilterOptions, page, pageSize int) ([]CreditListing, error)
+	GetSellerListings(ctx context.Context, userID uuid.UUID, page PageRequest) (*Page[CreditListing], error)
+	GetActiveListings(ctx context.Context, filter *FilterOptions, page PageRequest) (*Page[CreditListing], error)
 	GetActiveListingByID(ctx context.Context, id uuid.UUID) (*CreditListing, error)
 
 	CreateListing(ctx context.Context, userID uuid.UUID, req CreateListingRequest) (*CreditListing, error)
 	UpdateListing(ctx context.Context, userID, listingID uuid.UUID, req UpdateListingRequest) (*CreditListing, error)
 	DeleteListing(ctx context.Context, userID, listingID uuid.UUID) error
+	PublishListing(ctx context.Context, userID, listingID uuid.UUID) (*CreditListing, error)
+	CancelListing(ctx context.Context, userID, listingID uuid.UUID) (*CreditListing, error)
 
-	// PlaceOrder(ctx context.Context, userID, listingID uuid.UUID) error
+	// Reservation operations
+	ReserveCredits(ctx context.Context, userID, listingID uuid.UUID, req ReserveCreditsRequest) (*CreditReservation, error)
+	ConfirmReservation(ctx context.Context, userID, reservationID uuid.UUID) (*Purchase, error)
+	ReleaseReservation(ctx context.Context, userID, reservationID uuid.UUID) error
+	ReleaseExpiredReservations(ctx context.Context) (int64, error)
+	HandlePaymentWebhook(ctx context.Context, payload []byte, signature string) error
+
+	// Order operations
+	PlaceOrder(ctx context.Context, userID, listingID uuid.UUID, req PlaceOrderRequest) (*Purchase, error)
+	RefundPurchase(ctx context.Context, userID, purchaseID uuid.UUID) (*Purchase, error)
+	RetryPaymentRefunds(ctx context.Context) (int64, error)
+
+	// Wallet operations
+	GetWalletHoldings(ctx context.Context, userID uuid.UUID) ([]WalletHolding, error)
+	TransferCredits(ctx context.Context, userID, walletID uuid.UUID, req TransferCreditsRequest) (*CreditTransfer, error)
+	RetireCredits(ctx context.Context, userID, walletID uuid.UUID, req RetireCreditsRequest) (*CreditRetirement, error)
+	CreateResaleListing(ctx context.Context, userID, walletID uuid.UUID, req CreateResaleListingRequest) (*CreditListing, error)
+	CancelResaleListing(ctx context.Context, userID, listingID uuid.UUID) error
+	GetRetirement(ctx context.Context, userID, retirementID uuid.UUID) (*CreditRetirement, error)
+	VerifyCertificate(ctx context.Context, certificateID string) (*CertificateVerification, error)
+	CreateBuyOrder(ctx context.Context, userID uuid.UUID, req CreateBuyOrderRequest) (*BuyOrder, error)
+	GetBuyOrders(ctx context.Context, userID uuid.UUID, page, limit int) ([]BuyOrder, error)
+	CancelBuyOrder(ctx context.Context, userID, orderID uuid.UUID) error
 
 	// Auction operations
-	// GetActiveAuctions(ctx context.Context, filter FilterOptions, page, pageSize int) ([]ListingResponse, int, error)
-	// GetAuctionByID(ctx context.Context, id uuid.UUID) (*ListingResponse, error)
-	// CreateAuction(ctx context.Context, userID uuid.UUID, req CreateAuctionRequest) (*ListingResponse, error)
-	// PlaceBid(ctx context.Context, userID, auctionID uuid.UUID, amount float64) error
+	GetActiveAuctions(ctx context.Context, filter *FilterOptions, page, pageSize int) ([]CreditAuction, error)
+	GetAuctionByID(ctx context.Context, id uuid.UUID) (*CreditAuction, error)
+	CreateAuction(ctx context.Context, userID uuid.UUID, req CreateAuctionRequest) (*CreditAuction, error)
+	PlaceBid(ctx context.Context, userID, auctionID uuid.UUID, amount float64) (*AuctionBid, error)
+	SetProxyBid(ctx context.Context, userID, auctionID uuid.UUID, maxAmount float64) (*AuctionProxyBid, error)
+
+	// Market data operations
+	GetPriceCandles(ctx context.Context, q CandleQuery) ([]Candle, error)
+	GetMarketSummary(ctx context.Context) (*MarketSummary, error)
+	GetLandFeatures(ctx context.Context, activeOnly bool, bbox *BoundingBox) (*LandFeatureCollection, error)
 
 	// Verification operations
-	// CheckLandVerification(ctx context.Context, userID, landID uuid.UUID) error
-	// CheckCreditAvailability(ctx context.Context, creditID uuid.UUID, amount float64) error
+	CheckLandVerification(ctx context.Context, userID, landID uuid.UUID) error
+	PullUnlistableListings(ctx context.Context) (int64, error)
+	CheckCreditAvailability(ctx context.Context, userID, creditID uuid.UUID, amount float64) error
 }
 
 type Handler struct {
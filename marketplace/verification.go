@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// unlistableCondition matches listings whose credits may no longer be
+// offered: the land or its seller is not verified, or the batch has expired.
+const unlistableCondition = "lands.verification_status IS DISTINCT FROM 'verified' OR " +
+	"sellers.verification_status IS DISTINCT FROM 'verified' OR " +
+	"carbon_credits.expiration_date < CURRENT_DATE"
+
+// CheckLandVerification reports whether the seller may list credits from one
+// of their lands: both the land and the seller have to be verified.
+func (s *MarketSVC) CheckLandVerification(ctx context.Context, userID, landID uuid.UUID) error {
+	var land Land
+	if err := s.db.WithContext(ctx).
+		Preload("Seller").
+		Joins("JOIN sellers ON lands.owner_id = sellers.id").
+		Where("lands.id = ? AND sellers.user_id = ?", landID, userID).
+		First(&land).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLandNotFound
+		}
+		return err
+	}
+
+	return checkVerified(&land)
+}
+
+func checkVerified(land *Land) error {
+	if land.Seller.VerificationStatus != "verified" {
+		return ErrSellerNotVerified
+	}
+	if land.VerificationStatus != "verified" {
+		return ErrLandNotVerified
+	}
+	return nil
+}
+
+// checkListable checks that a batch's credits may be offered on an active
+// listing: its land and the land's seller are verified and it has not
+// expired.
+func checkListable(tx *gorm.DB, creditID uuid.UUID) error {
+	var credit CarbonCredit
+	if err := tx.Preload("Land").
+		Preload("Land.Seller").
+		Where("id = ?", creditID).
+		First(&credit).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCreditNotFound
+		}
+		return err
+	}
+
+	if err := checkVerified(&credit.Land); err != nil {
+		return err
+	}
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	if credit.ExpirationDate != nil && credit.ExpirationDate.Before(today) {
+		return ErrCreditsExpired
+	}
+
+	return nil
+}
+
+// isUnlistable reports whether err from checkListable means the credits may
+// not be offered, as opposed to the check itself failing.
+func isUnlistable(err error) bool {
+	return errors.Is(err, ErrLandNotVerified) ||
+		errors.Is(err, ErrSellerNotVerified) ||
+		errors.Is(err, ErrCreditsExpired)
+}
+
+// PullUnlistableListings moves active listings whose land or seller lost
+// verification, or whose batch expired, back to draft and releases the holds
+// on them. Sellers can publish them again once the land and seller are
+// verified; resellers can only cancel theirs. Every batch is pulled in its
+// own transaction, so it is safe to run from several instances at once.
+func (s *MarketSVC) PullUnlistableListings(ctx context.Context) (int64, error) {
+	var creditIDs []uuid.UUID
+	if err := s.db.WithContext(ctx).
+		Model(&CreditListing{}).
+		Joins("JOIN carbon_credits ON credit_listings.carbon_credits_id = carbon_credits.id").
+		Joins("JOIN lands ON carbon_credits.land_id = lands.id").
+		Joins("JOIN sellers ON lands.owner_id = sellers.id").
+		Where("credit_listings.status = ?", "active").
+		Where(unlistableCondition).
+		Distinct().
+		Pluck("credit_listings.carbon_credits_id", &creditIDs).Error; err != nil {
+		return 0, err
+	}
+
+	var pulled int64
+	for _, creditID := range creditIDs {
+		err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			n, err := pullBatchListings(tx, creditID)
+			pulled += n
+			return err
+		})
+		if err != nil {
+			return pulled, err
+		}
+	}
+
+	return pulled, nil
+}
+
+// pullBatchListings moves a batch's active listings to draft if its credits
+// may no longer be listed. The batch is locked before its listings, the same
+// order every sale takes, and checked again under the lock.
+func pullBatchListings(tx *gorm.DB, creditID uuid.UUID) (int64, error) {
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ?", creditID).
+		First(&CarbonCredit{}).Error; err != nil {
+		return 0, err
+	}
+
+	if err := checkListable(tx, creditID); !isUnlistable(err) {
+		return 0, err
+	}
+
+	var listings []CreditListing
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("carbon_credits_id = ? AND status = ?", creditID, "active").
+		Find(&listings).Error; err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	for i := range listings {
+		if err := pullListing(tx, &listings[i], now); err != nil {
+			return 0, err
+		}
+		if err := tx.Model(&listings[i]).Updates(map[string]interface{}{
+			"status":     listings[i].Status,
+			"updated_at": now,
+		}).Error; err != nil {
+			return 0, err
+		}
+	}
+
+	return int64(len(listings)), nil
+}
+
+// pullListing takes an active listing off the market, back to draft, and
+// releases its holds, which could no longer be confirmed. It is the only way
+// a listing returns to draft; sellers cannot unpublish. The caller persists
+// the listing.
+func pullListing(tx *gorm.DB, listing *CreditListing, now time.Time) error {
+	if listing.Status != "active" {
+		return ErrInvalidTransition
+	}
+	if err := releaseListingHolds(tx, listing.ID, now); err != nil {
+		return err
+	}
+
+	listing.Status = "draft"
+	return nil
+}